@@ -1,6 +1,14 @@
 package main
 
 import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
 	"github.com/conductorone/baton-sdk/pkg/field"
 	"github.com/spf13/viper"
 )
@@ -12,23 +20,184 @@ var (
 		field.WithRequired(true),
 	)
 
+	scimTokenField = field.StringField(
+		"scim-token",
+		field.WithDescription("An incident.io SCIM token, needed to create and delete user accounts"),
+		field.WithIsSecret(true),
+	)
+
+	fallbackBaseRoleField = field.StringField(
+		"fallback-base-role",
+		field.WithDescription("The ID of the base role users are moved to when their base role is revoked. Base roles cannot be revoked without it"),
+	)
+
+	teamCatalogTypeField = field.StringField(
+		"team-catalog-type",
+		field.WithDescription("The ID or name of the catalog type that holds teams, e.g. Team. Teams are only synced when set"),
+	)
+
+	teamMembersAttributeField = field.StringField(
+		"team-members-attribute",
+		field.WithDescription("The ID or name of the team catalog attribute that lists team members"),
+		field.WithDefaultValue("Members"),
+	)
+
+	catalogTypesField = field.StringSliceField(
+		"catalog-types",
+		field.WithDescription("The IDs or names of catalog types to sync. Attributes of type User become entitlements"),
+	)
+
+	overrideDurationField = field.StringField(
+		"override-duration",
+		field.WithDescription("How long schedule overrides created by provisioning last, e.g. 4h or 30m"),
+		field.WithDefaultValue("4h"),
+	)
+
+	coverageGapWindowField = field.StringField(
+		"coverage-gap-window",
+		field.WithDescription("How far ahead to look for unassigned schedule shifts to report on schedule profiles, e.g. 72h. 0 turns it off"),
+		field.WithDefaultValue("168h"),
+	)
+
+	syncIncidentsField = field.BoolField(
+		"sync-incidents",
+		field.WithDescription("Sync incidents and the users holding their roles. Incidents are not synced unless set"),
+	)
+
+	incidentStatusCategoriesField = field.StringSliceField(
+		"incident-status-categories",
+		field.WithDescription("Only sync incidents whose status is in one of these categories: triage, declared, merged, canceled, live, learning, closed, paused"),
+	)
+
+	incidentsCreatedAfterField = field.StringField(
+		"incidents-created-after",
+		field.WithDescription("Only sync incidents created on or after this date (2006-01-02)"),
+	)
+
+	baseURLField = field.StringField(
+		"base-url",
+		field.WithDescription("The incident.io API base URL, without the API version path"),
+		field.WithDefaultValue(client.DefaultBaseURL),
+	)
+
+	caBundleField = field.StringField(
+		"ca-bundle",
+		field.WithDescription("Path to a PEM file of CA certificates to trust in addition to the system roots"),
+	)
+
+	proxyURLField = field.StringField(
+		"proxy-url",
+		field.WithDescription("URL of an HTTP proxy to send API requests through"),
+	)
+
 	// ConfigurationFields defines the external configuration required for the
 	// connector to run. Note: these fields can be marked as optional or
 	// required.
 
-	ConfigurationFields = []field.SchemaField{tokenField}
+	ConfigurationFields = []field.SchemaField{
+		tokenField,
+		scimTokenField,
+		fallbackBaseRoleField,
+		teamCatalogTypeField,
+		teamMembersAttributeField,
+		catalogTypesField,
+		overrideDurationField,
+		coverageGapWindowField,
+		syncIncidentsField,
+		incidentStatusCategoriesField,
+		incidentsCreatedAfterField,
+		baseURLField,
+		caBundleField,
+		proxyURLField,
+	}
 
 	// FieldRelationships defines relationships between the fields listed in
 	// ConfigurationFields that can be automatically validated. For example, a
 	// username and password can be required together, or an access token can be
 	// marked as mutually exclusive from the username password pair.
 	FieldRelationships = []field.SchemaFieldRelationship{}
+
+	// incidentStatusCategories are the lifecycle stages incident statuses are grouped into.
+	incidentStatusCategories = []string{"triage", "declared", "merged", "canceled", "live", "learning", "closed", "paused"}
 )
 
+// incidentDateLayout is the format of incidents-created-after.
+const incidentDateLayout = "2006-01-02"
+
 // ValidateConfig is run after the configuration is loaded, and should return an
 // error if it isn't valid. Implementing this function is optional, it only
 // needs to perform extra validations that cannot be encoded with configuration
 // parameters.
 func ValidateConfig(v *viper.Viper) error {
+	if baseURL := v.GetString(baseURLField.FieldName); baseURL != "" {
+		if err := validateHTTPURL(baseURL); err != nil {
+			return fmt.Errorf("invalid %s: %w", baseURLField.FieldName, err)
+		}
+	}
+
+	if proxyURL := v.GetString(proxyURLField.FieldName); proxyURL != "" {
+		if err := validateHTTPURL(proxyURL); err != nil {
+			return fmt.Errorf("invalid %s: %w", proxyURLField.FieldName, err)
+		}
+	}
+
+	if overrideDuration := v.GetString(overrideDurationField.FieldName); overrideDuration != "" {
+		duration, err := time.ParseDuration(overrideDuration)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", overrideDurationField.FieldName, err)
+		}
+
+		if duration <= 0 {
+			return fmt.Errorf("invalid %s: %q must be positive", overrideDurationField.FieldName, overrideDuration)
+		}
+	}
+
+	if coverageGapWindow := v.GetString(coverageGapWindowField.FieldName); coverageGapWindow != "" {
+		duration, err := time.ParseDuration(coverageGapWindow)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", coverageGapWindowField.FieldName, err)
+		}
+
+		if duration < 0 {
+			return fmt.Errorf("invalid %s: %q must not be negative", coverageGapWindowField.FieldName, coverageGapWindow)
+		}
+	}
+
+	for _, category := range v.GetStringSlice(incidentStatusCategoriesField.FieldName) {
+		if !slices.Contains(incidentStatusCategories, category) {
+			return fmt.Errorf("invalid %s: %q is not one of %s", incidentStatusCategoriesField.FieldName, category, strings.Join(incidentStatusCategories, ", "))
+		}
+	}
+
+	if createdAfter := v.GetString(incidentsCreatedAfterField.FieldName); createdAfter != "" {
+		if _, err := time.Parse(incidentDateLayout, createdAfter); err != nil {
+			return fmt.Errorf("invalid %s: %q is not a date (2006-01-02)", incidentsCreatedAfterField.FieldName, createdAfter)
+		}
+	}
+
+	if caBundle := v.GetString(caBundleField.FieldName); caBundle != "" {
+		if _, err := os.Stat(caBundle); err != nil {
+			return fmt.Errorf("invalid %s: %w", caBundleField.FieldName, err)
+		}
+	}
+
+	return nil
+}
+
+// validateHTTPURL checks that rawURL is an absolute http or https URL.
+func validateHTTPURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must use http or https", rawURL)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", rawURL)
+	}
+
 	return nil
 }
@@ -14,7 +14,71 @@ func TestConfigs(t *testing.T) {
 	)
 
 	testCases := []test.TestCase{
-		// Add test cases here.
+		{
+			Configs: map[string]string{"token": "secret"},
+			IsValid: true,
+			Message: "token only",
+		},
+		{
+			Configs: map[string]string{},
+			IsValid: false,
+			Message: "missing token",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "base-url": "http://localhost:8080"},
+			IsValid: true,
+			Message: "local base url",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "base-url": "api.incident.io"},
+			IsValid: false,
+			Message: "base url without scheme",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "proxy-url": "ftp://proxy.internal"},
+			IsValid: false,
+			Message: "proxy url with unsupported scheme",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "ca-bundle": "/nonexistent/ca.pem"},
+			IsValid: false,
+			Message: "missing ca bundle",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "override-duration": "90m"},
+			IsValid: true,
+			Message: "override duration",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "override-duration": "a while"},
+			IsValid: false,
+			Message: "unparseable override duration",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "coverage-gap-window": "0"},
+			IsValid: true,
+			Message: "coverage gap look-ahead turned off",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "coverage-gap-window": "-24h"},
+			IsValid: false,
+			Message: "negative coverage gap window",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "incident-status-categories": "live", "incidents-created-after": "2025-01-01"},
+			IsValid: true,
+			Message: "incident filters",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "incident-status-categories": "ongoing"},
+			IsValid: false,
+			Message: "unknown incident status category",
+		},
+		{
+			Configs: map[string]string{"token": "secret", "incidents-created-after": "last week"},
+			IsValid: false,
+			Message: "unparseable incidents created after date",
+		},
 	}
 
 	test.ExerciseTestCases(t, configurationSchema, ValidateConfig, testCases)
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	exportFormatCSV  = "csv"
+	exportFormatJSON = "json"
+
+	exportDateLayout = "2006-01-02"
+)
+
+// shiftRecord is a final shift of a schedule as written by the export-shifts command.
+type shiftRecord struct {
+	ScheduleID   string `json:"schedule_id"`
+	ScheduleName string `json:"schedule_name"`
+	client.CurrentShift
+}
+
+// newExportShiftsCommand returns the export-shifts command, which writes who was on call for every
+// schedule over a date range, for evidence that on-call coverage was held by the expected people.
+func newExportShiftsCommand(ctx context.Context, v *viper.Viper) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-shifts",
+		Short: "Export the final on-call shifts of all schedules over a date range as CSV or JSON",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if err := ValidateConfig(v); err != nil {
+				return err
+			}
+
+			from, err := parseExportTime(cmd, "from")
+			if err != nil {
+				return err
+			}
+
+			to, err := parseExportTime(cmd, "to")
+			if err != nil {
+				return err
+			}
+
+			if to.IsZero() {
+				to = time.Now()
+			}
+
+			if !from.Before(to) {
+				return fmt.Errorf("--from must be before --to")
+			}
+
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return err
+			}
+
+			if format != exportFormatCSV && format != exportFormatJSON {
+				return fmt.Errorf("--format must be %s or %s, got %q", exportFormatCSV, exportFormatJSON, format)
+			}
+
+			apiClient, err := newExportClient(v)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if output, _ := cmd.Flags().GetString("output"); output != "" {
+				file, err := os.Create(output)
+				if err != nil {
+					return fmt.Errorf("error creating %s: %w", output, err)
+				}
+				defer file.Close()
+
+				out = file
+			}
+
+			return exportShifts(ctx, apiClient, from, to, format, out)
+		},
+	}
+
+	cmd.Flags().String("from", "", "Start of the export window, as a date (2006-01-02) or RFC 3339 time (required)")
+	cmd.Flags().String("to", "", "End of the export window, as a date (2006-01-02) or RFC 3339 time (default now)")
+	cmd.Flags().String("format", exportFormatCSV, "Output format: csv, json")
+	cmd.Flags().StringP("output", "o", "", "File to write the export to (default stdout)")
+	_ = cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+// newExportClient builds an API client from the connector configuration.
+func newExportClient(v *viper.Viper) (*client.APIClient, error) {
+	accessToken := v.GetString(tokenField.FieldName)
+	if accessToken == "" {
+		return nil, fmt.Errorf("missing access token")
+	}
+
+	httpClient, err := client.NewHTTPClient(v.GetString(caBundleField.FieldName), v.GetString(proxyURLField.FieldName))
+	if err != nil {
+		return nil, err
+	}
+
+	return client.NewClient(
+		accessToken,
+		uhttp.NewBaseHttpClient(httpClient),
+		client.WithBaseURL(v.GetString(baseURLField.FieldName)),
+	), nil
+}
+
+// parseExportTime reads a date or RFC 3339 time flag. Unset flags return the zero time.
+func parseExportTime(cmd *cobra.Command, name string) (time.Time, error) {
+	value, err := cmd.Flags().GetString(name)
+	if err != nil || value == "" {
+		return time.Time{}, err
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	t, err := time.Parse(exportDateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --%s %q: expected a date (2006-01-02) or RFC 3339 time", name, value)
+	}
+
+	return t, nil
+}
+
+// exportShifts writes the final shifts of every schedule between from and to in the given format.
+func exportShifts(ctx context.Context, apiClient *client.APIClient, from, to time.Time, format string, w io.Writer) error {
+	var records []shiftRecord
+
+	pageToken := ""
+	for {
+		schedules, nextPageToken, _, err := apiClient.ListSchedules(ctx, client.PageOptions{
+			After:    pageToken,
+			PageSize: client.ItemsPerPage,
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching schedules: %w", err)
+		}
+
+		for _, schedule := range schedules {
+			shifts, _, err := apiClient.ListScheduleEntries(ctx, schedule.ID, from, to)
+			if err != nil {
+				return fmt.Errorf("error fetching shifts of schedule %s: %w", schedule.ID, err)
+			}
+
+			for _, shift := range shifts {
+				records = append(records, shiftRecord{
+					ScheduleID:   schedule.ID,
+					ScheduleName: schedule.Name,
+					CurrentShift: shift,
+				})
+			}
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+
+		pageToken = nextPageToken
+	}
+
+	if format == exportFormatJSON {
+		if records == nil {
+			records = []shiftRecord{}
+		}
+
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(records)
+	}
+
+	writer := csv.NewWriter(w)
+	rows := [][]string{{"schedule_id", "schedule_name", "rotation_id", "user_id", "user_name", "user_email", "start_at", "end_at"}}
+	for _, record := range records {
+		rows = append(rows, []string{
+			record.ScheduleID,
+			record.ScheduleName,
+			record.RotationID,
+			record.User.ID,
+			record.User.Name,
+			record.User.Email,
+			record.StartAt,
+			record.EndAt,
+		})
+	}
+
+	return writer.WriteAll(rows)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-incident-io/pkg/test"
+)
+
+const (
+	exportSchedulesBody = `{"schedules":[{"id":"01JQ77YN7BRVRA81T9STQ41HB2","name":"Primary"}],"pagination_meta":{"page_size":100}}`
+
+	exportEntriesFirstPage = `{"schedule_entries":{"final":[` +
+		`{"rotation_id":"01JQ77YN7BRVRA81T9STQ41ROT","start_at":"2025-03-17T09:00:00Z","end_at":"2025-03-24T09:00:00Z",` +
+		`"user":{"id":"01JPWQP39ZE3X1NRHC3PJAWZVQ","name":"Alejandro","email":"alejandro@example.com"}}],` +
+		`"scheduled":[]},"pagination_meta":{"after":"page2"}}`
+
+	exportEntriesSecondPage = `{"schedule_entries":{"final":[` +
+		`{"rotation_id":"01JQ77YN7BRVRA81T9STQ41ROT","start_at":"2025-03-24T09:00:00Z","end_at":"2025-03-31T09:00:00Z",` +
+		`"user":{"id":"01JPWQNM50YGKQYFJYW61BBPD7","name":"test","email":"test@example.com"}}]},"pagination_meta":{}}`
+)
+
+// newExportTestClient serves one schedule with two pages of entries.
+func newExportTestClient(requests *[]test.Request) *client.APIClient {
+	return test.NewRoutingTestClient(nil,
+		test.WithHandler("GET /v2/schedules", func(*http.Request, []byte) (int, string) {
+			return http.StatusOK, exportSchedulesBody
+		}),
+		test.WithHandler("GET /v2/schedule_entries", func(req *http.Request, _ []byte) (int, string) {
+			if req.URL.Query().Get("after") == "page2" {
+				return http.StatusOK, exportEntriesSecondPage
+			}
+
+			return http.StatusOK, exportEntriesFirstPage
+		}),
+		test.WithRecorder(requests),
+	)
+}
+
+// entryQueries returns the queries of the schedule entry requests among requests.
+func entryQueries(requests []test.Request) []string {
+	var queries []string
+	for _, req := range requests {
+		if req.Path == "/v2/schedule_entries" {
+			queries = append(queries, req.Query)
+		}
+	}
+
+	return queries
+}
+
+func TestExportShifts_CSV(t *testing.T) {
+	var requests []test.Request
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	if err := exportShifts(context.Background(), newExportTestClient(&requests), from, to, exportFormatCSV, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := "schedule_id,schedule_name,rotation_id,user_id,user_name,user_email,start_at,end_at\n" +
+		"01JQ77YN7BRVRA81T9STQ41HB2,Primary,01JQ77YN7BRVRA81T9STQ41ROT,01JPWQP39ZE3X1NRHC3PJAWZVQ,Alejandro,alejandro@example.com,2025-03-17T09:00:00Z,2025-03-24T09:00:00Z\n" +
+		"01JQ77YN7BRVRA81T9STQ41HB2,Primary,01JQ77YN7BRVRA81T9STQ41ROT,01JPWQNM50YGKQYFJYW61BBPD7,test,test@example.com,2025-03-24T09:00:00Z,2025-03-31T09:00:00Z\n"
+	if out.String() != expected {
+		t.Errorf("Unexpected CSV export:\n%s", out.String())
+	}
+
+	queries := entryQueries(requests)
+	if len(queries) != 2 {
+		t.Fatalf("Expected 2 pages of entries to be requested, got %d", len(queries))
+	}
+
+	if !strings.Contains(queries[0], "entry_window_start=2025-03-01T00%3A00%3A00Z") || !strings.Contains(queries[0], "entry_window_end=2025-04-01T00%3A00%3A00Z") {
+		t.Errorf("Expected the export window in the query, got %s", queries[0])
+	}
+}
+
+func TestExportShifts_JSON(t *testing.T) {
+	var requests []test.Request
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	var out bytes.Buffer
+	if err := exportShifts(context.Background(), newExportTestClient(&requests), from, to, exportFormatJSON, &out); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var records []shiftRecord
+	if err := json.Unmarshal(out.Bytes(), &records); err != nil {
+		t.Fatalf("Expected a JSON array, got %v", err)
+	}
+
+	if len(records) != 2 || records[1].User.ID != "01JPWQNM50YGKQYFJYW61BBPD7" || records[1].ScheduleName != "Primary" {
+		t.Errorf("Unexpected JSON export %+v", records)
+	}
+}
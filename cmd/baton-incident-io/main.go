@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/conductorone/baton-incident-io/pkg/connector"
+	"github.com/conductorone/baton-sdk/pkg/cli"
 	"github.com/conductorone/baton-sdk/pkg/config"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/field"
@@ -20,7 +22,7 @@ var version = "dev"
 func main() {
 	ctx := context.Background()
 
-	_, cmd, err := config.DefineConfiguration(
+	v, cmd, err := config.DefineConfiguration(
 		ctx,
 		"baton-incident-io",
 		getConnector,
@@ -35,6 +37,12 @@ func main() {
 
 	cmd.Version = version
 
+	_, err = cli.AddCommand(cmd, v, &field.Configuration{Fields: ConfigurationFields}, newExportShiftsCommand(ctx, v))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
 	err = cmd.Execute()
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
@@ -51,11 +59,42 @@ func getConnector(ctx context.Context, v *viper.Viper) (types.ConnectorServer, e
 
 	accessToken := v.GetString(tokenField.FieldName)
 
+	// Validated by ValidateConfig, an empty value keeps the connector default.
+	overrideDuration, _ := time.ParseDuration(v.GetString(overrideDurationField.FieldName))
+	// Validated by ValidateConfig, an empty value turns the look-ahead off like 0 does.
+	coverageGapWindow, _ := time.ParseDuration(v.GetString(coverageGapWindowField.FieldName))
+	// Validated by ValidateConfig, an empty value leaves the zero time, which does not filter.
+	incidentsCreatedAfter, _ := time.Parse(incidentDateLayout, v.GetString(incidentsCreatedAfterField.FieldName))
+
 	if accessToken == "" {
 		return nil, fmt.Errorf("missing access token")
 	}
 
-	cb, err := connector.New(ctx, accessToken)
+	cb, err := connector.New(
+		ctx,
+		accessToken,
+		connector.WithSCIMToken(v.GetString(scimTokenField.FieldName)),
+		connector.WithFallbackBaseRole(v.GetString(fallbackBaseRoleField.FieldName)),
+		// Defined by the SDK alongside the connector's own fields.
+		connector.WithProvisioning(v.GetBool("provisioning")),
+		connector.WithTeamCatalog(
+			v.GetString(teamCatalogTypeField.FieldName),
+			v.GetString(teamMembersAttributeField.FieldName),
+		),
+		connector.WithCatalogTypes(v.GetStringSlice(catalogTypesField.FieldName)),
+		connector.WithOverrideDuration(overrideDuration),
+		connector.WithCoverageGapWindow(coverageGapWindow),
+		connector.WithIncidents(v.GetBool(syncIncidentsField.FieldName)),
+		connector.WithIncidentFilter(
+			v.GetStringSlice(incidentStatusCategoriesField.FieldName),
+			incidentsCreatedAfter,
+		),
+		connector.WithBaseURL(v.GetString(baseURLField.FieldName)),
+		connector.WithTransport(
+			v.GetString(caBundleField.FieldName),
+			v.GetString(proxyURLField.FieldName),
+		),
+	)
 	if err != nil {
 		l.Error("error creating connector", zap.Error(err))
 		return nil, err
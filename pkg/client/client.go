@@ -1,38 +1,126 @@
 package client
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/ratelimit"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
 	"go.uber.org/zap"
 )
 
 const (
-	baseDomain           = "https://api.incident.io/v2"
-	getUsersEndpoint     = "/users"
-	getSchedulesEndpoint = "/schedules"
+	// DefaultBaseURL is the incident.io API used when no base URL is configured.
+	DefaultBaseURL = "https://api.incident.io"
+
+	getIdentityEndpoint  = "/v1/identity"
+	getUsersEndpoint     = "/v2/users"
+	getSchedulesEndpoint = "/v2/schedules"
+
+	getScheduleOverridesEndpoint = "/v2/schedule_overrides"
+	getScheduleEntriesEndpoint   = "/v2/schedule_entries"
+
+	getEscalationPathsEndpoint = "/v2/escalation_paths"
+	getCatalogTypesEndpoint    = "/v2/catalog_types"
+	getCatalogEntriesEndpoint  = "/v2/catalog_entries"
+
+	getIncidentsEndpoint     = "/v2/incidents"
+	getIncidentRolesEndpoint = "/v2/incident_roles"
+
+	getWorkflowsEndpoint = "/v2/workflows"
+
+	// incidentCreatedAfterLayout is the date format of the created_at filter of the incidents endpoint.
+	incidentCreatedAfterLayout = "2006-01-02"
+
+	scimUsersEndpoint  = "/scim/v2/Users"
+	scimGroupsEndpoint = "/scim/v2/Groups"
+
+	// maxRetries bounds how many times a rate limited request is retried.
+	maxRetries     = 5
+	initialBackoff = time.Second
+	maxBackoff     = 30 * time.Second
 )
 
+// readOnlyScheduleFields are returned when reading a schedule but are not accepted when updating it.
+var readOnlyScheduleFields = []string{"id", "created_at", "updated_at", "current_shifts"}
+
+// ErrSCIMTokenMissing is returned by the SCIM methods when the client has no SCIM token.
+var ErrSCIMTokenMissing = errors.New("incident.io: a SCIM token is required to provision accounts")
+
 type APIClient struct {
-	apiToken string
-	wrapper  *uhttp.BaseHttpClient
+	apiToken  string
+	scimToken string
+	baseURL   string
+	wrapper   *uhttp.BaseHttpClient
+}
+
+// ClientOpt configures optional behaviour of the API client.
+type ClientOpt func(c *APIClient)
+
+// WithBaseURL overrides the incident.io API base URL, e.g. to target a regional endpoint or a local stub.
+func WithBaseURL(baseURL string) ClientOpt {
+	return func(c *APIClient) {
+		if baseURL != "" {
+			c.baseURL = baseURL
+		}
+	}
+}
+
+// WithSCIMToken sets the SCIM token used to create and delete users. incident.io issues SCIM
+// tokens separately from API keys, and SCIM requests are rejected when authenticated with an API key.
+func WithSCIMToken(scimToken string) ClientOpt {
+	return func(c *APIClient) {
+		c.scimToken = scimToken
+	}
 }
 
 // NewClient creates a new API client with the provided API token.
-func NewClient(apiToken string, httpClient *uhttp.BaseHttpClient) *APIClient {
+func NewClient(apiToken string, httpClient *uhttp.BaseHttpClient, opts ...ClientOpt) *APIClient {
 	if httpClient == nil {
 		httpClient = uhttp.NewBaseHttpClient(http.DefaultClient)
 	}
 
-	return &APIClient{
+	c := &APIClient{
 		wrapper:  httpClient,
 		apiToken: apiToken,
+		baseURL:  DefaultBaseURL,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// GetIdentity retrieves the identity and roles of the API key used by the client.
+func (c *APIClient) GetIdentity(ctx context.Context) (*Identity, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res IdentityResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getIdentityEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating IdentityResponse URL: %s", err))
+		return nil, nil, err
 	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting identity: %s", err))
+		return nil, nil, err
+	}
+
+	return &res.Identity, annotation, nil
 }
 
 // ListSchedules retrieves a list of schedules from the API.
@@ -41,7 +129,7 @@ func (c *APIClient) ListSchedules(ctx context.Context, options PageOptions) ([]S
 	var res ScheduleResponse
 	var annotation annotations.Annotations
 
-	queryUrl, err := url.JoinPath(baseDomain, getSchedulesEndpoint)
+	queryUrl, err := url.JoinPath(c.baseURL, getSchedulesEndpoint)
 	if err != nil {
 		l.Error(fmt.Sprintf("Error creating UserResponse URL: %s", err))
 		return nil, "", nil, err
@@ -56,13 +144,466 @@ func (c *APIClient) ListSchedules(ctx context.Context, options PageOptions) ([]S
 	return res.Schedule, res.Meta.After, annotation, nil
 }
 
+// GetSchedule retrieves a single schedule by its ID from the API.
+func (c *APIClient) GetSchedule(ctx context.Context, scheduleID string) (*Schedule, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res GetScheduleResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getSchedulesEndpoint, scheduleID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating GetSchedule URL: %s", err))
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting schedule: %s", err))
+		return nil, nil, err
+	}
+
+	return &res.Schedule, annotation, nil
+}
+
+// GetScheduleForUpdate retrieves a single schedule by its ID, bypassing the HTTP cache, together with
+// the update that writes it back as read, including the fields the client does not model. Changes are
+// made by passing the update to UpdateSchedule.
+func (c *APIClient) GetScheduleForUpdate(ctx context.Context, scheduleID string) (*Schedule, *ScheduleUpdate, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res struct {
+		Schedule json.RawMessage `json:"schedule"`
+	}
+
+	queryUrl, err := url.JoinPath(c.baseURL, getSchedulesEndpoint, scheduleID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating GetScheduleForUpdate URL: %s", err))
+		return nil, nil, nil, err
+	}
+
+	annotation, err := c.getUncached(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting schedule: %s", err))
+		return nil, nil, nil, err
+	}
+
+	var schedule Schedule
+	if err := json.Unmarshal(res.Schedule, &schedule); err != nil {
+		return nil, nil, nil, err
+	}
+
+	var update ScheduleUpdate
+	if err := json.Unmarshal(res.Schedule, &update); err != nil {
+		return nil, nil, nil, err
+	}
+
+	for _, field := range readOnlyScheduleFields {
+		delete(update.Extra, field)
+	}
+
+	return &schedule, &update, annotation, nil
+}
+
+// ListScheduleEntries retrieves the final shifts of a schedule, after overrides are applied,
+// that overlap the window between from and to. Every page of entries is read.
+func (c *APIClient) ListScheduleEntries(ctx context.Context, scheduleID string, from, to time.Time) ([]CurrentShift, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var entries []CurrentShift
+	var annotation annotations.Annotations
+
+	queryUrl, err := url.JoinPath(c.baseURL, getScheduleEntriesEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating ListScheduleEntries URL: %s", err))
+		return nil, nil, err
+	}
+
+	pageToken := ""
+	for {
+		var res ScheduleEntriesResponse
+
+		annotation, err = c.getResourcesFromAPI(ctx, queryUrl, &res,
+			WithQueryParam("schedule_id", scheduleID),
+			WithQueryParam("entry_window_start", from.UTC().Format(time.RFC3339)),
+			WithQueryParam("entry_window_end", to.UTC().Format(time.RFC3339)),
+			WithPageAfter(pageToken),
+		)
+		if err != nil {
+			l.Error(fmt.Sprintf("Error getting schedule entries: %s", err))
+			return nil, nil, err
+		}
+
+		entries = append(entries, res.ScheduleEntries.Final...)
+
+		if res.Meta.After == "" || res.Meta.After == pageToken {
+			break
+		}
+
+		pageToken = res.Meta.After
+	}
+
+	return entries, annotation, nil
+}
+
+// UpdateSchedule replaces the rotations of a schedule, writing back the other fields of update as
+// returned by GetScheduleForUpdate. incident.io has no conditional update, so an edit made to the
+// schedule after update was read is overwritten.
+func (c *APIClient) UpdateSchedule(ctx context.Context, scheduleID string, update *ScheduleUpdate, rotations []Rotation) (*Schedule, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res GetScheduleResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getSchedulesEndpoint, scheduleID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating UpdateSchedule URL: %s", err))
+		return nil, nil, err
+	}
+
+	body := *update
+	body.Config.Rotations = newRotationUpdates(rotations, update.Config.Rotations)
+
+	_, annotation, err := c.doRequest(ctx, http.MethodPut, queryUrl, &res, UpdateScheduleRequest{Schedule: body})
+	if err != nil {
+		l.Error(fmt.Sprintf("Error updating schedule: %s", err))
+		return nil, nil, err
+	}
+
+	return &res.Schedule, annotation, nil
+}
+
+// ListScheduleOverrides retrieves a page of the overrides of a schedule that overlap the window
+// between from and to.
+func (c *APIClient) ListScheduleOverrides(ctx context.Context, scheduleID string, from, to time.Time, options PageOptions) ([]ScheduleOverride, string, annotations.Annotations, error) {
+	return c.listScheduleOverrides(ctx, c.getResourcesFromAPI, scheduleID, from, to, options)
+}
+
+// ListScheduleOverridesForUpdate is ListScheduleOverrides bypassing the HTTP cache, for deciding
+// which overrides to create or delete.
+func (c *APIClient) ListScheduleOverridesForUpdate(ctx context.Context, scheduleID string, from, to time.Time, options PageOptions) ([]ScheduleOverride, string, annotations.Annotations, error) {
+	return c.listScheduleOverrides(ctx, c.getUncached, scheduleID, from, to, options)
+}
+
+func (c *APIClient) listScheduleOverrides(
+	ctx context.Context,
+	get func(ctx context.Context, endpointUrl string, res any, reqOptions ...ReqOpt) (annotations.Annotations, error),
+	scheduleID string,
+	from, to time.Time,
+	options PageOptions,
+) ([]ScheduleOverride, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res ScheduleOverrideResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getScheduleOverridesEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating ListScheduleOverrides URL: %s", err))
+		return nil, "", nil, err
+	}
+
+	annotation, err := get(ctx, queryUrl, &res,
+		WithQueryParam("schedule_id", scheduleID),
+		WithQueryParam("start_at", from.UTC().Format(time.RFC3339)),
+		WithQueryParam("end_at", to.UTC().Format(time.RFC3339)),
+		WithPageAfter(options.After),
+		WithPageLimit(options.PageSize),
+	)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting schedule overrides: %s", err))
+		return nil, "", nil, err
+	}
+
+	return res.Overrides, res.Meta.After, annotation, nil
+}
+
+// CreateScheduleOverride puts a user on call for a rotation of a schedule between the given times.
+func (c *APIClient) CreateScheduleOverride(ctx context.Context, override CreateScheduleOverrideRequest) (*ScheduleOverride, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res CreateScheduleOverrideResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getScheduleOverridesEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating CreateScheduleOverride URL: %s", err))
+		return nil, nil, err
+	}
+
+	_, annotation, err := c.doRequest(ctx, http.MethodPost, queryUrl, &res, override)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating schedule override: %s", err))
+		return nil, nil, err
+	}
+
+	return &res.Override, annotation, nil
+}
+
+// DeleteScheduleOverride deletes an override, ending it at once if it has already started.
+func (c *APIClient) DeleteScheduleOverride(ctx context.Context, overrideID string) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	queryUrl, err := url.JoinPath(c.baseURL, getScheduleOverridesEndpoint, overrideID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating DeleteScheduleOverride URL: %s", err))
+		return nil, err
+	}
+
+	_, annotation, err := c.doRequest(ctx, http.MethodDelete, queryUrl, nil, nil)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error deleting schedule override: %s", err))
+		return nil, err
+	}
+
+	return annotation, nil
+}
+
+// newRotationUpdates converts rotations to the form accepted by the update schedule endpoint.
+// Rotations are edited in place, so the fields the client does not model are carried over from
+// the current rotation version at the same position when it has the same ID.
+func newRotationUpdates(rotations []Rotation, current []RotationUpdate) []RotationUpdate {
+	updates := make([]RotationUpdate, 0, len(rotations))
+	for i, rotation := range rotations {
+		var extra map[string]json.RawMessage
+		if i < len(current) && current[i].ID == rotation.ID {
+			extra = current[i].Extra
+		}
+
+		users := make([]UserReference, 0, len(rotation.Users))
+		for _, user := range rotation.Users {
+			users = append(users, UserReference{ID: user.ID})
+		}
+
+		updates = append(updates, RotationUpdate{
+			ID:              rotation.ID,
+			Name:            rotation.Name,
+			Users:           users,
+			Layers:          rotation.Layers,
+			EffectiveFrom:   rotation.EffectiveFrom,
+			HandoverStartAt: rotation.HandoverStartAt,
+			Handovers:       rotation.Handovers,
+			WorkingInterval: rotation.WorkingInterval,
+			Extra:           extra,
+		})
+	}
+
+	return updates
+}
+
+// ListEscalationPaths retrieves a list of escalation paths from the API.
+func (c *APIClient) ListEscalationPaths(ctx context.Context, options PageOptions) ([]EscalationPath, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res EscalationPathResponse
+	var annotation annotations.Annotations
+
+	queryUrl, err := url.JoinPath(c.baseURL, getEscalationPathsEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating EscalationPathResponse URL: %s", err))
+		return nil, "", nil, err
+	}
+
+	annotation, err = c.getResourcesFromAPI(ctx, queryUrl, &res, WithPageAfter(options.After), WithPageLimit(options.PageSize))
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting resources: %s", err))
+		return nil, "", nil, err
+	}
+
+	return res.EscalationPaths, res.Meta.After, annotation, nil
+}
+
+// GetEscalationPath retrieves a single escalation path by its ID from the API.
+func (c *APIClient) GetEscalationPath(ctx context.Context, escalationPathID string) (*EscalationPath, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res GetEscalationPathResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getEscalationPathsEndpoint, escalationPathID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating GetEscalationPath URL: %s", err))
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting escalation path: %s", err))
+		return nil, nil, err
+	}
+
+	return &res.EscalationPath, annotation, nil
+}
+
+// ListCatalogTypes retrieves every catalog type from the API. The endpoint is not paginated.
+func (c *APIClient) ListCatalogTypes(ctx context.Context) ([]CatalogType, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res CatalogTypeResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getCatalogTypesEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating CatalogTypeResponse URL: %s", err))
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting resources: %s", err))
+		return nil, nil, err
+	}
+
+	return res.CatalogTypes, annotation, nil
+}
+
+// ListCatalogEntries retrieves a list of entries of the given catalog type from the API.
+func (c *APIClient) ListCatalogEntries(ctx context.Context, catalogTypeID string, options PageOptions) ([]CatalogEntry, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res CatalogEntryResponse
+	var annotation annotations.Annotations
+
+	queryUrl, err := url.JoinPath(c.baseURL, getCatalogEntriesEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating CatalogEntryResponse URL: %s", err))
+		return nil, "", nil, err
+	}
+
+	annotation, err = c.getResourcesFromAPI(ctx, queryUrl, &res,
+		WithQueryParam("catalog_type_id", catalogTypeID),
+		WithPageAfter(options.After),
+		WithPageLimit(options.PageSize),
+	)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting resources: %s", err))
+		return nil, "", nil, err
+	}
+
+	return res.CatalogEntries, res.Meta.After, annotation, nil
+}
+
+// GetCatalogEntry retrieves a single catalog entry by its ID from the API.
+func (c *APIClient) GetCatalogEntry(ctx context.Context, catalogEntryID string) (*CatalogEntry, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res GetCatalogEntryResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getCatalogEntriesEndpoint, catalogEntryID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating GetCatalogEntry URL: %s", err))
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting catalog entry: %s", err))
+		return nil, nil, err
+	}
+
+	return &res.CatalogEntry, annotation, nil
+}
+
+// ListIncidents retrieves a page of the incidents matching the filter from the API.
+func (c *APIClient) ListIncidents(ctx context.Context, filter IncidentFilter, options PageOptions) ([]Incident, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res IncidentResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getIncidentsEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating ListIncidents URL: %s", err))
+		return nil, "", nil, err
+	}
+
+	reqOptions := []ReqOpt{
+		WithQueryParamValues("status_category[one_of]", filter.StatusCategories),
+		WithPageAfter(options.After),
+		WithPageLimit(options.PageSize),
+	}
+
+	if !filter.CreatedAfter.IsZero() {
+		reqOptions = append(reqOptions, WithQueryParam("created_at[gte]", filter.CreatedAfter.Format(incidentCreatedAfterLayout)))
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res, reqOptions...)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting incidents: %s", err))
+		return nil, "", nil, err
+	}
+
+	return res.Incidents, res.Meta.After, annotation, nil
+}
+
+// GetIncident retrieves a single incident by its ID from the API.
+func (c *APIClient) GetIncident(ctx context.Context, incidentID string) (*Incident, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res GetIncidentResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getIncidentsEndpoint, incidentID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating GetIncident URL: %s", err))
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting incident: %s", err))
+		return nil, nil, err
+	}
+
+	return &res.Incident, annotation, nil
+}
+
+// ListIncidentRoles retrieves every incident role from the API. The endpoint is not paginated.
+func (c *APIClient) ListIncidentRoles(ctx context.Context) ([]IncidentRole, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res IncidentRolesResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getIncidentRolesEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating ListIncidentRoles URL: %s", err))
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting incident roles: %s", err))
+		return nil, nil, err
+	}
+
+	return res.IncidentRoles, annotation, nil
+}
+
+// ListWorkflows retrieves every workflow from the API. The endpoint is not paginated.
+func (c *APIClient) ListWorkflows(ctx context.Context) ([]Workflow, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res WorkflowResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getWorkflowsEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating ListWorkflows URL: %s", err))
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting workflows: %s", err))
+		return nil, nil, err
+	}
+
+	return res.Workflows, annotation, nil
+}
+
+// GetWorkflow retrieves a single workflow, with its steps, by its ID from the API.
+func (c *APIClient) GetWorkflow(ctx context.Context, workflowID string) (*Workflow, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res GetWorkflowResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getWorkflowsEndpoint, workflowID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating GetWorkflow URL: %s", err))
+		return nil, nil, err
+	}
+
+	annotation, err := c.getResourcesFromAPI(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting workflow: %s", err))
+		return nil, nil, err
+	}
+
+	return &res.Workflow, annotation, nil
+}
+
 // ListUsers retrieves a list of users from the API.
 func (c *APIClient) ListUsers(ctx context.Context, options PageOptions) ([]User, string, annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 	var res UserResponse
 	var annotation annotations.Annotations
 
-	queryUrl, err := url.JoinPath(baseDomain, getUsersEndpoint)
+	queryUrl, err := url.JoinPath(c.baseURL, getUsersEndpoint)
 	if err != nil {
 		l.Error(fmt.Sprintf("Error creating UserResponse URL: %s", err))
 		return nil, "", nil, err
@@ -77,9 +618,227 @@ func (c *APIClient) ListUsers(ctx context.Context, options PageOptions) ([]User,
 	return res.Users, res.Meta.After, annotation, nil
 }
 
+// GetUser retrieves a single user by its ID from the API. The HTTP cache is bypassed, as the user
+// is only read to base a role update on.
+func (c *APIClient) GetUser(ctx context.Context, userID string) (*User, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res UserDetailResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getUsersEndpoint, userID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating GetUser URL: %s", err))
+		return nil, nil, err
+	}
+
+	annotation, err := c.getUncached(ctx, queryUrl, &res)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting user: %s", err))
+		return nil, nil, err
+	}
+
+	return &res.User, annotation, nil
+}
+
+// UpdateUserRoles replaces the base role and custom roles assigned to a user.
+func (c *APIClient) UpdateUserRoles(ctx context.Context, userID string, baseRoleID string, customRoleIDs []string) (*User, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res UserDetailResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, getUsersEndpoint, userID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating UpdateUserRoles URL: %s", err))
+		return nil, nil, err
+	}
+
+	if customRoleIDs == nil {
+		customRoleIDs = []string{}
+	}
+
+	body := UpdateUserRolesRequest{
+		BaseRoleID:    baseRoleID,
+		CustomRoleIDs: customRoleIDs,
+	}
+
+	_, annotation, err := c.doRequest(ctx, http.MethodPut, queryUrl, &res, body)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error updating user roles: %s", err))
+		return nil, nil, err
+	}
+
+	return &res.User, annotation, nil
+}
+
+// CreateSCIMUser creates a user through the SCIM API.
+func (c *APIClient) CreateSCIMUser(ctx context.Context, user SCIMUser) (*SCIMUser, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res SCIMUser
+
+	queryUrl, err := url.JoinPath(c.baseURL, scimUsersEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating CreateSCIMUser URL: %s", err))
+		return nil, nil, err
+	}
+
+	annotation, err := c.doSCIMRequest(ctx, http.MethodPost, queryUrl, &res, user)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating SCIM user: %s", err))
+		return nil, nil, err
+	}
+
+	return &res, annotation, nil
+}
+
+// DeleteSCIMUser removes a user from the organisation through the SCIM API.
+func (c *APIClient) DeleteSCIMUser(ctx context.Context, userID string) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	queryUrl, err := url.JoinPath(c.baseURL, scimUsersEndpoint, userID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating DeleteSCIMUser URL: %s", err))
+		return nil, err
+	}
+
+	annotation, err := c.doSCIMRequest(ctx, http.MethodDelete, queryUrl, nil, nil)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error deleting SCIM user: %s", err))
+		return nil, err
+	}
+
+	return annotation, nil
+}
+
+// ListSCIMGroups retrieves a page of SCIM groups. The page token is the SCIM start index of the
+// page, and the returned token is empty once the last page has been read.
+func (c *APIClient) ListSCIMGroups(ctx context.Context, options PageOptions) ([]SCIMGroup, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res SCIMGroupListResponse
+
+	queryUrl, err := url.JoinPath(c.baseURL, scimGroupsEndpoint)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating ListSCIMGroups URL: %s", err))
+		return nil, "", nil, err
+	}
+
+	startIndex := 1
+	if options.After != "" {
+		startIndex, err = strconv.Atoi(options.After)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("invalid SCIM page token %q: %w", options.After, err)
+		}
+	}
+
+	count := options.PageSize
+	if count == 0 {
+		count = ItemsPerPage
+	}
+
+	annotation, err := c.doSCIMRequest(ctx, http.MethodGet, queryUrl, &res, nil,
+		WithQueryParam("startIndex", strconv.Itoa(startIndex)),
+		WithQueryParam("count", strconv.Itoa(count)),
+	)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting SCIM groups: %s", err))
+		return nil, "", nil, err
+	}
+
+	nextPageToken := ""
+	if next := startIndex + len(res.Resources); len(res.Resources) > 0 && next <= res.TotalResults {
+		nextPageToken = strconv.Itoa(next)
+	}
+
+	return res.Resources, nextPageToken, annotation, nil
+}
+
+// GetSCIMGroup retrieves a single SCIM group, with its members, by its ID.
+func (c *APIClient) GetSCIMGroup(ctx context.Context, groupID string) (*SCIMGroup, annotations.Annotations, error) {
+	return c.getSCIMGroup(ctx, c.wrapper.Do, groupID)
+}
+
+// GetSCIMGroupForUpdate is GetSCIMGroup bypassing the HTTP cache, for deciding whether a
+// membership has to be added or removed.
+func (c *APIClient) GetSCIMGroupForUpdate(ctx context.Context, groupID string) (*SCIMGroup, annotations.Annotations, error) {
+	return c.getSCIMGroup(ctx, c.doUncached, groupID)
+}
+
+func (c *APIClient) getSCIMGroup(ctx context.Context, do func(*http.Request, ...uhttp.DoOption) (*http.Response, error), groupID string) (*SCIMGroup, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	var res SCIMGroup
+
+	if c.scimToken == "" {
+		return nil, nil, ErrSCIMTokenMissing
+	}
+
+	queryUrl, err := url.JoinPath(c.baseURL, scimGroupsEndpoint, groupID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating GetSCIMGroup URL: %s", err))
+		return nil, nil, err
+	}
+
+	_, annotation, err := c.send(ctx, do, c.scimToken, http.MethodGet, queryUrl, &res, nil)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error getting SCIM group: %s", err))
+		return nil, nil, err
+	}
+
+	return &res, annotation, nil
+}
+
+// AddSCIMGroupMember adds a user to a SCIM group.
+func (c *APIClient) AddSCIMGroupMember(ctx context.Context, groupID, userID string) (annotations.Annotations, error) {
+	return c.patchSCIMGroup(ctx, groupID, SCIMPatchOperation{
+		Op:    "add",
+		Path:  "members",
+		Value: []SCIMMember{{Value: userID}},
+	})
+}
+
+// RemoveSCIMGroupMember removes a user from a SCIM group.
+func (c *APIClient) RemoveSCIMGroupMember(ctx context.Context, groupID, userID string) (annotations.Annotations, error) {
+	return c.patchSCIMGroup(ctx, groupID, SCIMPatchOperation{
+		Op:   "remove",
+		Path: fmt.Sprintf("members[value eq %q]", userID),
+	})
+}
+
+// patchSCIMGroup applies a PATCH operation to a SCIM group.
+func (c *APIClient) patchSCIMGroup(ctx context.Context, groupID string, operation SCIMPatchOperation) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	queryUrl, err := url.JoinPath(c.baseURL, scimGroupsEndpoint, groupID)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error creating PatchSCIMGroup URL: %s", err))
+		return nil, err
+	}
+
+	body := SCIMPatchRequest{
+		Schemas:    []string{SCIMPatchOpSchema},
+		Operations: []SCIMPatchOperation{operation},
+	}
+
+	annotation, err := c.doSCIMRequest(ctx, http.MethodPatch, queryUrl, nil, body)
+	if err != nil {
+		l.Error(fmt.Sprintf("Error updating SCIM group: %s", err))
+		return nil, err
+	}
+
+	return annotation, nil
+}
+
+// doSCIMRequest executes a request against the SCIM API, authenticated with the SCIM token.
+func (c *APIClient) doSCIMRequest(ctx context.Context, method, endpointUrl string, res any, body any,
+	reqOptions ...ReqOpt) (annotations.Annotations, error) {
+	if c.scimToken == "" {
+		return nil, ErrSCIMTokenMissing
+	}
+
+	_, annotation, err := c.doRequestWithToken(ctx, c.scimToken, method, endpointUrl, res, body, reqOptions...)
+
+	return annotation, err
+}
+
 // getResourcesFromAPI makes a GET request to the specified API endpoint.
 func (c *APIClient) getResourcesFromAPI(ctx context.Context, urlAddress string, res any, reqOptions ...ReqOpt) (annotations.Annotations, error) {
-	_, annotation, err := c.doRequest(ctx, http.MethodGet, urlAddress, &res, reqOptions...)
+	_, annotation, err := c.doRequest(ctx, http.MethodGet, urlAddress, &res, nil, reqOptions...)
 	if err != nil {
 		return nil, err
 	}
@@ -87,9 +846,65 @@ func (c *APIClient) getResourcesFromAPI(ctx context.Context, urlAddress string,
 	return annotation, nil
 }
 
-// doRequest executes an HTTP request and processes the response.
-func (c *APIClient) doRequest(ctx context.Context, method, endpointUrl string, res any,
+// doRequest executes an HTTP request authenticated with the API key and processes the response.
+// When body is non-nil it is encoded as the JSON request body.
+func (c *APIClient) doRequest(ctx context.Context, method, endpointUrl string, res any, body any,
 	reqOptions ...ReqOpt) (http.Header, annotations.Annotations, error) {
+	return c.doRequestWithToken(ctx, c.apiToken, method, endpointUrl, res, body, reqOptions...)
+}
+
+// doRequestWithToken executes an HTTP request authenticated with the given bearer token.
+func (c *APIClient) doRequestWithToken(ctx context.Context, token, method, endpointUrl string, res any, body any,
+	reqOptions ...ReqOpt) (http.Header, annotations.Annotations, error) {
+	return c.send(ctx, c.wrapper.Do, token, method, endpointUrl, res, body, reqOptions...)
+}
+
+// getUncached makes a GET request that bypasses the HTTP cache, for reads that a write is based on.
+// Other cached responses are left alone.
+func (c *APIClient) getUncached(ctx context.Context, endpointUrl string, res any, reqOptions ...ReqOpt) (annotations.Annotations, error) {
+	_, annotation, err := c.send(ctx, c.doUncached, c.apiToken, http.MethodGet, endpointUrl, res, nil, reqOptions...)
+
+	return annotation, err
+}
+
+// doUncached executes a request with the wrapped HTTP client directly, skipping the wrapper's cache,
+// and applies the response options the way the wrapper does.
+func (c *APIClient) doUncached(request *http.Request, options ...uhttp.DoOption) (*http.Response, error) {
+	response, err := c.wrapper.HttpClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return response, err
+	}
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return response, fmt.Errorf("unexpected status code: %d", response.StatusCode)
+	}
+
+	wrapperResponse := uhttp.WrapperResponse{
+		Header:     response.Header,
+		Body:       body,
+		Status:     response.Status,
+		StatusCode: response.StatusCode,
+	}
+
+	for _, option := range options {
+		if err := option(&wrapperResponse); err != nil {
+			return response, err
+		}
+	}
+
+	return response, nil
+}
+
+// send builds and executes a request with do, retrying it while it is rate limited.
+func (c *APIClient) send(ctx context.Context, do func(*http.Request, ...uhttp.DoOption) (*http.Response, error),
+	token, method, endpointUrl string, res any, body any, reqOptions ...ReqOpt) (http.Header, annotations.Annotations, error) {
 	logger := ctxzap.Extract(ctx)
 
 	urlAddress, err := url.Parse(endpointUrl)
@@ -104,30 +919,85 @@ func (c *APIClient) doRequest(ctx context.Context, method, endpointUrl string, r
 	options := []uhttp.RequestOption{
 		uhttp.WithContentTypeJSONHeader(),
 		uhttp.WithAcceptJSONHeader(),
-		uhttp.WithBearerToken(c.apiToken),
+		uhttp.WithBearerToken(token),
 	}
 
-	request, err := c.wrapper.NewRequest(ctx, method, urlAddress, options...)
-	if err != nil {
-		logger.Error("failed to create request", zap.Error(err))
-		return nil, nil, err
+	if body != nil {
+		options = append(options, uhttp.WithJSONBody(body))
 	}
 
-	annotation := annotations.Annotations{}
 	doOptions := []uhttp.DoOption{}
 
 	if res != nil {
 		doOptions = append(doOptions, uhttp.WithJSONResponse(res))
 	}
 
-	response, err := c.wrapper.Do(request, doOptions...)
-	if response != nil && response.Body != nil {
-		defer response.Body.Close()
+	for attempt := 0; ; attempt++ {
+		request, err := c.wrapper.NewRequest(ctx, method, urlAddress, options...)
+		if err != nil {
+			logger.Error("failed to create request", zap.Error(err))
+			return nil, nil, err
+		}
+
+		annotation := annotations.Annotations{}
+
+		response, err := do(request, doOptions...)
+		if response != nil {
+			rateLimit, rlErr := ratelimit.ExtractRateLimitData(response.StatusCode, &response.Header)
+			if rlErr != nil {
+				rateLimit = nil
+			}
+
+			if rateLimit != nil {
+				annotation.WithRateLimiting(rateLimit)
+			}
+
+			if err != nil && response.StatusCode >= http.StatusBadRequest {
+				err = newAPIError(response, rateLimit, err)
+			}
+
+			if response.Body != nil {
+				_ = response.Body.Close()
+			}
+		}
+
+		if err == nil {
+			return response.Header, annotation, nil
+		}
+
+		if response == nil || response.StatusCode != http.StatusTooManyRequests || attempt >= maxRetries {
+			return nil, annotation, fmt.Errorf("error in Do: %w", err)
+		}
+
+		wait := retryDelay(response.Header, attempt)
+		logger.Warn(
+			"rate limited by incident.io, retrying",
+			zap.String("url", urlAddress.String()),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("wait", wait),
+		)
+
+		select {
+		case <-ctx.Done():
+			return nil, annotation, ctx.Err()
+		case <-time.After(wait):
+		}
 	}
+}
 
-	if err != nil {
-		return nil, annotation, fmt.Errorf("error in Do: %w", err)
+// retryDelay returns how long to wait before retrying a rate limited request. The Retry-After
+// header is honored when present, otherwise the delay doubles with each attempt. Both are capped
+// at maxBackoff.
+func retryDelay(header http.Header, attempt int) time.Duration {
+	delay := initialBackoff << attempt
+
+	if retryAfter := header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+			delay = time.Duration(seconds) * time.Second
+		} else if at, err := http.ParseTime(retryAfter); err == nil {
+			delay = max(time.Until(at), 0)
+		}
 	}
 
-	return response.Header, annotation, nil
+	return min(delay, maxBackoff)
 }
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// APIError is the error envelope returned by the incident.io API for unsuccessful requests.
+type APIError struct {
+	Type      string           `json:"type"`
+	Status    int              `json:"status"`
+	RequestID string           `json:"request_id"`
+	Errors    []APIErrorDetail `json:"errors"`
+
+	// RateLimit holds the rate limit state reported alongside the error, if any.
+	RateLimit *v2.RateLimitDescription `json:"-"`
+
+	cause error
+}
+
+type APIErrorDetail struct {
+	Code    string          `json:"code"`
+	Message string          `json:"message"`
+	Source  *APIErrorSource `json:"source,omitempty"`
+}
+
+type APIErrorSource struct {
+	Field string `json:"field"`
+}
+
+// Error returns a message including the status, error type, details and request ID.
+func (e *APIError) Error() string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "incident.io API error: status %d", e.Status)
+	if e.Type != "" {
+		fmt.Fprintf(&sb, " (%s)", e.Type)
+	}
+
+	var details []string
+	for _, detail := range e.Errors {
+		message := detail.Message
+		if detail.Code != "" {
+			message = fmt.Sprintf("%s: %s", detail.Code, message)
+		}
+		if detail.Source != nil && detail.Source.Field != "" {
+			message = fmt.Sprintf("%s [field %s]", message, detail.Source.Field)
+		}
+		details = append(details, message)
+	}
+	if len(details) > 0 {
+		fmt.Fprintf(&sb, ": %s", strings.Join(details, "; "))
+	}
+
+	if e.RequestID != "" {
+		fmt.Fprintf(&sb, " (request_id: %s)", e.RequestID)
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the error reported by the HTTP client for the request.
+func (e *APIError) Unwrap() error {
+	return e.cause
+}
+
+// Code maps the HTTP status of the error to a gRPC code.
+func (e *APIError) Code() codes.Code {
+	switch e.Status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusRequestTimeout:
+		return codes.DeadlineExceeded
+	case http.StatusTooManyRequests:
+		return codes.Unavailable
+	}
+
+	if e.Status >= http.StatusInternalServerError {
+		return codes.Unavailable
+	}
+
+	return codes.Unknown
+}
+
+// GRPCStatus lets the gRPC status package, and so the SDK's retry logic, read the code of the error.
+func (e *APIError) GRPCStatus() *status.Status {
+	st := status.New(e.Code(), e.Error())
+
+	if e.RateLimit != nil {
+		if withDetails, err := st.WithDetails(e.RateLimit); err == nil {
+			st = withDetails
+		}
+	}
+
+	return st
+}
+
+// newAPIError decodes the error envelope from an unsuccessful response. Bodies that are not
+// an incident.io error envelope still produce an APIError carrying the HTTP status.
+func newAPIError(response *http.Response, rateLimit *v2.RateLimitDescription, cause error) *APIError {
+	apiErr := &APIError{}
+
+	if response.Body != nil {
+		body, err := io.ReadAll(response.Body)
+		if err == nil && len(body) > 0 {
+			_ = json.Unmarshal(body, apiErr)
+		}
+	}
+
+	apiErr.Status = response.StatusCode
+	apiErr.RateLimit = rateLimit
+	apiErr.cause = cause
+
+	if apiErr.RequestID == "" {
+		apiErr.RequestID = response.Header.Get("X-Request-Id")
+	}
+
+	return apiErr
+}
@@ -1,8 +1,11 @@
 package client
 
 import (
+	"encoding/json"
 	"net/url"
+	"reflect"
 	"strconv"
+	"strings"
 )
 
 // Default number of items per page if not specified.
@@ -39,5 +42,62 @@ func WithQueryParam(key string, value string) ReqOpt {
 	}
 }
 
+// WithQueryParamValues sets a query parameter to each of the given values, for filters accepting several.
+func WithQueryParamValues(key string, values []string) ReqOpt {
+	return func(reqURL *url.URL) {
+		if len(values) > 0 {
+			q := reqURL.Query()
+			q[key] = values
+			reqURL.RawQuery = q.Encode()
+		}
+	}
+}
+
 // ReqOpt defines a function that modifies a request URL.
 type ReqOpt func(reqURL *url.URL)
+
+// marshalWithExtra encodes v, a struct, and adds the fields in extra that v does not encode itself.
+func marshalWithExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return data, err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	for key, value := range extra {
+		if _, ok := fields[key]; !ok {
+			fields[key] = value
+		}
+	}
+
+	return json.Marshal(fields)
+}
+
+// unmarshalWithExtra decodes data into v, a pointer to a struct, and keeps the fields v has no field for in extra.
+func unmarshalWithExtra(data []byte, v any, extra *map[string]json.RawMessage) error {
+	if err := json.Unmarshal(data, v); err != nil {
+		return err
+	}
+
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	t := reflect.TypeOf(v).Elem()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		delete(fields, name)
+	}
+
+	*extra = nil
+	if len(fields) > 0 {
+		*extra = fields
+	}
+
+	return nil
+}
@@ -1,5 +1,21 @@
 package client
 
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+type IdentityResponse struct {
+	Identity Identity `json:"identity"`
+}
+
+type Identity struct {
+	Name         string   `json:"name"`
+	Roles        []string `json:"roles"`
+	DashboardURL string   `json:"dashboard_url"`
+}
+
 type UserResponse struct {
 	Users []User `json:"users"`
 	Meta  Meta   `json:"pagination_meta"`
@@ -10,10 +26,157 @@ type ScheduleResponse struct {
 	Meta     Meta       `json:"pagination_meta"`
 }
 
+type GetScheduleResponse struct {
+	Schedule Schedule `json:"schedule"`
+}
+
+type UserDetailResponse struct {
+	User User `json:"user"`
+}
+
+type UpdateUserRolesRequest struct {
+	BaseRoleID    string   `json:"base_role_id"`
+	CustomRoleIDs []string `json:"custom_role_ids"`
+}
+
+type UpdateScheduleRequest struct {
+	Schedule ScheduleUpdate `json:"schedule"`
+}
+
+// ScheduleUpdate is the body of a schedule update. Fields of the schedule the client does not model
+// are kept in Extra, so that a schedule that was read can be written back without clearing them.
+type ScheduleUpdate struct {
+	Name     string                     `json:"name"`
+	Timezone string                     `json:"timezone"`
+	Config   ScheduleConfigUpdate       `json:"config"`
+	Extra    map[string]json.RawMessage `json:"-"`
+}
+
+type ScheduleConfigUpdate struct {
+	Rotations []RotationUpdate           `json:"rotations"`
+	Extra     map[string]json.RawMessage `json:"-"`
+}
+
+// RotationUpdate is a rotation version as accepted by the update schedule endpoint,
+// which references users by ID only. Fields the client does not model are kept in Extra.
+type RotationUpdate struct {
+	ID              string                     `json:"id"`
+	Name            string                     `json:"name"`
+	Users           []UserReference            `json:"users"`
+	Layers          []RotationLayer            `json:"layers"`
+	EffectiveFrom   *time.Time                 `json:"effective_from,omitempty"`
+	HandoverStartAt *time.Time                 `json:"handover_start_at,omitempty"`
+	Handovers       []RotationHandover         `json:"handovers"`
+	WorkingInterval []WorkingInterval          `json:"working_interval"`
+	Extra           map[string]json.RawMessage `json:"-"`
+}
+
+func (u ScheduleUpdate) MarshalJSON() ([]byte, error) {
+	type scheduleUpdate ScheduleUpdate
+	return marshalWithExtra(scheduleUpdate(u), u.Extra)
+}
+
+func (u *ScheduleUpdate) UnmarshalJSON(data []byte) error {
+	type scheduleUpdate ScheduleUpdate
+	return unmarshalWithExtra(data, (*scheduleUpdate)(u), &u.Extra)
+}
+
+func (u ScheduleConfigUpdate) MarshalJSON() ([]byte, error) {
+	type scheduleConfigUpdate ScheduleConfigUpdate
+	return marshalWithExtra(scheduleConfigUpdate(u), u.Extra)
+}
+
+func (u *ScheduleConfigUpdate) UnmarshalJSON(data []byte) error {
+	type scheduleConfigUpdate ScheduleConfigUpdate
+	return unmarshalWithExtra(data, (*scheduleConfigUpdate)(u), &u.Extra)
+}
+
+func (u RotationUpdate) MarshalJSON() ([]byte, error) {
+	type rotationUpdate RotationUpdate
+	return marshalWithExtra(rotationUpdate(u), u.Extra)
+}
+
+func (u *RotationUpdate) UnmarshalJSON(data []byte) error {
+	type rotationUpdate RotationUpdate
+	return unmarshalWithExtra(data, (*rotationUpdate)(u), &u.Extra)
+}
+
+type UserReference struct {
+	ID string `json:"id"`
+}
+
+type ScheduleEntriesResponse struct {
+	ScheduleEntries ScheduleEntries `json:"schedule_entries"`
+	Meta            Meta            `json:"pagination_meta"`
+}
+
+// ScheduleEntries holds the shifts of a schedule: Scheduled as the rotations plan them, Overrides
+// as overridden, and Final as actually worked once overrides are applied.
+type ScheduleEntries struct {
+	Final     []CurrentShift `json:"final"`
+	Overrides []CurrentShift `json:"overrides"`
+	Scheduled []CurrentShift `json:"scheduled"`
+}
+
+type ScheduleOverrideResponse struct {
+	Overrides []ScheduleOverride `json:"schedule_overrides"`
+	Meta      Meta               `json:"pagination_meta"`
+}
+
+type CreateScheduleOverrideResponse struct {
+	Override ScheduleOverride `json:"override"`
+}
+
+// ScheduleOverride puts a user on call for a layer of a rotation in place of whoever the rotation
+// schedules between StartAt and EndAt.
+type ScheduleOverride struct {
+	ID         string    `json:"id"`
+	ScheduleID string    `json:"schedule_id"`
+	RotationID string    `json:"rotation_id"`
+	LayerID    string    `json:"layer_id"`
+	User       ShiftUser `json:"user"`
+	StartAt    time.Time `json:"start_at"`
+	EndAt      time.Time `json:"end_at"`
+}
+
+type CreateScheduleOverrideRequest struct {
+	ScheduleID string        `json:"schedule_id"`
+	RotationID string        `json:"rotation_id"`
+	LayerID    string        `json:"layer_id,omitempty"`
+	User       UserReference `json:"user"`
+	StartAt    time.Time     `json:"start_at"`
+	EndAt      time.Time     `json:"end_at"`
+}
+
 type User struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Email       string     `json:"email"`
+	SlackUserID string     `json:"slack_user_id"`
+	Role        string     `json:"role"`
+	BaseRole    Role       `json:"base_role"`
+	CustomRoles []Role     `json:"custom_roles"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+
+	// Status is "active" for users who can sign in, or "deactivated" once they are removed
+	// from the organisation, for example by SCIM. DeactivatedAt is set for deactivated users.
+	Status        string     `json:"status"`
+	DeactivatedAt *time.Time `json:"deactivated_at,omitempty"`
+}
+
+const UserStatusDeactivated = "deactivated"
+
+// Deactivated reports whether the user has been removed from the organisation.
+func (u User) Deactivated() bool {
+	return u.DeactivatedAt != nil || strings.EqualFold(u.Status, UserStatusDeactivated)
+}
+
+type Role struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Slug        string `json:"slug"`
 }
 
 type Meta struct {
@@ -24,6 +187,7 @@ type Meta struct {
 type Schedule struct {
 	ID            string         `json:"id"`
 	Name          string         `json:"name"`
+	Timezone      string         `json:"timezone"`
 	CurrentShifts []CurrentShift `json:"current_shifts"`
 	Config        ScheduleConfig `json:"config"`
 }
@@ -32,10 +196,35 @@ type ScheduleConfig struct {
 	Rotation []Rotation `json:"rotations"`
 }
 
+// Rotation is one version of a schedule rotation. A rotation that has been edited is returned
+// once per version, each with the same ID and the time it takes effect in EffectiveFrom.
 type Rotation struct {
-	ID    string      `json:"id"`
-	Name  string      `json:"name"`
-	Users []ShiftUser `json:"users"`
+	ID              string             `json:"id"`
+	Name            string             `json:"name"`
+	Users           []ShiftUser        `json:"users"`
+	Layers          []RotationLayer    `json:"layers"`
+	EffectiveFrom   *time.Time         `json:"effective_from,omitempty"`
+	HandoverStartAt *time.Time         `json:"handover_start_at,omitempty"`
+	Handovers       []RotationHandover `json:"handovers"`
+	WorkingInterval []WorkingInterval  `json:"working_interval"`
+}
+
+type RotationLayer struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// RotationHandover is how often shifts hand over, e.g. an Interval of 1 with an IntervalType of "weekly".
+type RotationHandover struct {
+	Interval     int    `json:"interval"`
+	IntervalType string `json:"interval_type"`
+}
+
+// WorkingInterval restricts a rotation to a time window on a weekday, in the schedule's timezone.
+type WorkingInterval struct {
+	Weekday   string `json:"weekday"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
 }
 
 type ListScheduleResponse struct {
@@ -54,3 +243,283 @@ type ShiftUser struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
 }
+
+type EscalationPathResponse struct {
+	EscalationPaths []EscalationPath `json:"escalation_paths"`
+	Meta            Meta             `json:"pagination_meta"`
+}
+
+type GetEscalationPathResponse struct {
+	EscalationPath EscalationPath `json:"escalation_path"`
+}
+
+type EscalationPath struct {
+	ID      string               `json:"id"`
+	Name    string               `json:"name"`
+	Path    []EscalationPathNode `json:"path"`
+	TeamIDs []string             `json:"team_ids"`
+}
+
+// EscalationPathNode is a step of an escalation path. Only the field matching Type is set.
+type EscalationPathNode struct {
+	ID     string                    `json:"id"`
+	Type   string                    `json:"type"`
+	Level  *EscalationPathNodeLevel  `json:"level,omitempty"`
+	IfElse *EscalationPathNodeIfElse `json:"if_else,omitempty"`
+}
+
+type EscalationPathNodeLevel struct {
+	Targets          []EscalationPathTarget `json:"targets"`
+	TimeToAckSeconds int                    `json:"time_to_ack_seconds"`
+}
+
+type EscalationPathNodeIfElse struct {
+	ThenPath []EscalationPathNode `json:"then_path"`
+	ElsePath []EscalationPathNode `json:"else_path"`
+}
+
+type EscalationPathTarget struct {
+	ID           string `json:"id"`
+	Type         string `json:"type"`
+	Urgency      string `json:"urgency"`
+	ScheduleMode string `json:"schedule_mode"`
+}
+
+type CatalogTypeResponse struct {
+	CatalogTypes []CatalogType `json:"catalog_types"`
+}
+
+type CatalogType struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	TypeName    string            `json:"type_name"`
+	Description string            `json:"description"`
+	Schema      CatalogTypeSchema `json:"schema"`
+}
+
+type CatalogTypeSchema struct {
+	Attributes []CatalogTypeAttribute `json:"attributes"`
+}
+
+type CatalogTypeAttribute struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Array bool   `json:"array"`
+}
+
+type CatalogEntryResponse struct {
+	CatalogEntries []CatalogEntry `json:"catalog_entries"`
+	Meta           Meta           `json:"pagination_meta"`
+}
+
+type GetCatalogEntryResponse struct {
+	CatalogEntry CatalogEntry `json:"catalog_entry"`
+}
+
+type CatalogEntry struct {
+	ID              string                             `json:"id"`
+	Name            string                             `json:"name"`
+	ExternalID      string                             `json:"external_id"`
+	Aliases         []string                           `json:"aliases"`
+	CatalogTypeID   string                             `json:"catalog_type_id"`
+	AttributeValues map[string]CatalogAttributeBinding `json:"attribute_values"`
+}
+
+// CatalogAttributeBinding holds the value of an entry attribute, either as a single value or an array.
+type CatalogAttributeBinding struct {
+	Value      *CatalogAttributeValue  `json:"value,omitempty"`
+	ArrayValue []CatalogAttributeValue `json:"array_value,omitempty"`
+}
+
+type CatalogAttributeValue struct {
+	Literal string `json:"literal"`
+	Label   string `json:"label"`
+}
+
+// Literals returns the literal values bound to the attribute, whether it holds one value or an array.
+func (b CatalogAttributeBinding) Literals() []string {
+	var literals []string
+
+	if b.Value != nil && b.Value.Literal != "" {
+		literals = append(literals, b.Value.Literal)
+	}
+
+	for _, value := range b.ArrayValue {
+		if value.Literal != "" {
+			literals = append(literals, value.Literal)
+		}
+	}
+
+	return literals
+}
+
+// SCIMUserSchema is the SCIM core schema URN of user resources.
+const SCIMUserSchema = "urn:ietf:params:scim:schemas:core:2.0:User"
+
+// SCIMUser is a user as read and written by incident.io's SCIM API. Its ID is the incident.io user ID.
+type SCIMUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *SCIMName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []SCIMEmail `json:"emails,omitempty"`
+	Active      bool        `json:"active"`
+}
+
+type SCIMName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary"`
+}
+
+// SCIMPatchOpSchema is the SCIM schema URN of PATCH requests.
+const SCIMPatchOpSchema = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+
+// SCIMGroupListResponse is a page of SCIM groups. SCIM pages are addressed by a 1-based start index.
+type SCIMGroupListResponse struct {
+	TotalResults int         `json:"totalResults"`
+	StartIndex   int         `json:"startIndex"`
+	ItemsPerPage int         `json:"itemsPerPage"`
+	Resources    []SCIMGroup `json:"Resources"`
+}
+
+// SCIMGroup is a group pushed to incident.io by an identity provider, which can be mapped to a
+// base or custom role in the incident.io dashboard.
+type SCIMGroup struct {
+	ID          string       `json:"id"`
+	DisplayName string       `json:"displayName"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	Members     []SCIMMember `json:"members"`
+}
+
+// SCIMMember references a user belonging to a SCIM group by its incident.io user ID.
+type SCIMMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations"`
+}
+
+type SCIMPatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value,omitempty"`
+}
+
+type IncidentResponse struct {
+	Incidents []Incident `json:"incidents"`
+	Meta      Meta       `json:"pagination_meta"`
+}
+
+type GetIncidentResponse struct {
+	Incident Incident `json:"incident"`
+}
+
+type Incident struct {
+	ID                      string                   `json:"id"`
+	Reference               string                   `json:"reference"`
+	Name                    string                   `json:"name"`
+	Summary                 string                   `json:"summary"`
+	Permalink               string                   `json:"permalink"`
+	Visibility              string                   `json:"visibility"`
+	Status                  IncidentStatus           `json:"incident_status"`
+	Severity                *IncidentSeverity        `json:"severity,omitempty"`
+	IncidentRoleAssignments []IncidentRoleAssignment `json:"incident_role_assignments"`
+	CreatedAt               *time.Time               `json:"created_at,omitempty"`
+	UpdatedAt               *time.Time               `json:"updated_at,omitempty"`
+}
+
+// IncidentStatus is the status of an incident. Category groups the custom statuses of an
+// organisation into the fixed lifecycle stages: triage, declared, merged, canceled, live,
+// learning, closed and paused.
+type IncidentStatus struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+}
+
+type IncidentSeverity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Rank int    `json:"rank"`
+}
+
+// IncidentRoleAssignment is a role of an incident along with the user holding it, if anyone does.
+type IncidentRoleAssignment struct {
+	Role     IncidentRole `json:"role"`
+	Assignee *User        `json:"assignee,omitempty"`
+}
+
+type IncidentRolesResponse struct {
+	IncidentRoles []IncidentRole `json:"incident_roles"`
+}
+
+// IncidentRole is a role that can be assigned on incidents, such as Incident Lead. RoleType is
+// "lead" for the incident lead, "reporter" for the reporter and "custom" for every other role.
+type IncidentRole struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Description  string     `json:"description"`
+	Instructions string     `json:"instructions"`
+	Shortform    string     `json:"shortform"`
+	RoleType     string     `json:"role_type"`
+	Required     bool       `json:"required"`
+	CreatedAt    *time.Time `json:"created_at,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// IncidentFilter narrows down the incidents listed. Empty fields do not filter.
+type IncidentFilter struct {
+	// StatusCategories keeps incidents whose status falls into one of the categories.
+	StatusCategories []string
+	// CreatedAfter keeps incidents created on or after the day of this time.
+	CreatedAfter time.Time
+}
+
+type WorkflowResponse struct {
+	Workflows []Workflow `json:"workflows"`
+}
+
+type GetWorkflowResponse struct {
+	Workflow Workflow `json:"workflow"`
+}
+
+// Workflow is an automation run on incidents. The list endpoint omits Steps, which are only
+// returned when a single workflow is read.
+type Workflow struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Folder          string          `json:"folder"`
+	Trigger         WorkflowTrigger `json:"trigger"`
+	State           string          `json:"state"`
+	RunsOnIncidents string          `json:"runs_on_incidents"`
+	Version         int             `json:"version"`
+	Steps           []WorkflowStep  `json:"steps"`
+}
+
+const WorkflowStateActive = "active"
+
+type WorkflowTrigger struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+}
+
+// WorkflowStep is an action of a workflow. Its parameters are bound the same way as catalog
+// attributes, to a literal value or an array of them, such as the IDs of users to page.
+type WorkflowStep struct {
+	ID            string                    `json:"id"`
+	Name          string                    `json:"name"`
+	Label         string                    `json:"label"`
+	ParamBindings []CatalogAttributeBinding `json:"param_bindings"`
+}
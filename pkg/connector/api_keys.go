@@ -0,0 +1,99 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// connectorAPIKeyID is the resource ID of the API key the connector runs with. incident.io does not
+// return an ID for the key and its name can be changed in the dashboard, so the ID is fixed.
+const connectorAPIKeyID = "connector"
+
+// apiKeyBuilder syncs incident.io API keys as secrets. The API has no endpoint listing the keys of
+// an organisation, only /v1/identity describing the key making the request, so the only key synced
+// is the one the connector runs with. Its creator and creation date are not exposed.
+type apiKeyBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.APIClient
+}
+
+// ResourceType returns the resource type associated with API keys.
+func (o *apiKeyBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return apiKeyResourceType
+}
+
+// List returns the API key used by the connector, with its roles in the secret profile.
+func (o *apiKeyBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	identity, annos, err := o.client.GetIdentity(ctx)
+	if err != nil {
+		l.Error("Error fetching API key identity", zap.Error(err))
+		return nil, "", nil, fmt.Errorf("error fetching API key identity: %w", err)
+	}
+
+	roles := make([]interface{}, 0, len(identity.Roles))
+	for _, role := range identity.Roles {
+		roles = append(roles, role)
+	}
+
+	profile := map[string]interface{}{
+		"roles":         roles,
+		"dashboard_url": identity.DashboardURL,
+	}
+
+	apiKeyResource, err := resource.NewSecretResource(
+		identity.Name,
+		apiKeyResourceType,
+		connectorAPIKeyID,
+		[]resource.SecretTraitOption{withSecretProfile(profile)},
+		resource.WithParentResourceID(parentResourceID),
+	)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("error creating API key resource: %w", err)
+	}
+
+	return []*v2.Resource{apiKeyResource}, "", annos, nil
+}
+
+// withSecretProfile sets the profile of a secret trait. The SDK has no option for it, unlike the
+// other traits.
+func withSecretProfile(profile map[string]interface{}) resource.SecretTraitOption {
+	return func(t *v2.SecretTrait) error {
+		p, err := structpb.NewStruct(profile)
+		if err != nil {
+			return err
+		}
+
+		t.Profile = p
+
+		return nil
+	}
+}
+
+// Entitlements always returns an empty slice for API keys.
+func (o *apiKeyBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for API keys.
+func (o *apiKeyBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// NewAPIKeyBuilder initializes a new API key builder.
+func NewAPIKeyBuilder(c *client.APIClient) *apiKeyBuilder {
+	return &apiKeyBuilder{
+		resourceType: apiKeyResourceType,
+		client:       c,
+	}
+}
@@ -0,0 +1,45 @@
+package connector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestAPIKeyBuilderList(t *testing.T) {
+	a := NewAPIKeyBuilder(test.NewRoutingTestClient(map[string]string{"/v1/identity": "identityMock.json"}))
+
+	resources, _, _, err := a.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resources) != 1 {
+		t.Fatalf("Expected the connector's own API key only, got %d keys", len(resources))
+	}
+
+	apiKey := resources[0]
+	if apiKey.Id.Resource != connectorAPIKeyID || apiKey.DisplayName != "baton" {
+		t.Errorf("Unexpected API key: %v", apiKey.Id)
+	}
+
+	annos := annotations.Annotations(apiKey.GetAnnotations())
+	secretTrait := &v2.SecretTrait{}
+	ok, err := annos.Pick(secretTrait)
+	if err != nil || !ok {
+		t.Fatalf("Expected a secret trait, got %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"roles":         []interface{}{"viewer", "schedules_reader", "catalog_viewer", "global_access"},
+		"dashboard_url": "https://app.incident.io/example",
+	}
+	if profile := secretTrait.GetProfile().AsMap(); !reflect.DeepEqual(profile, expected) {
+		t.Errorf("Unexpected profile: %v", profile)
+	}
+}
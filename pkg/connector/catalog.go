@@ -0,0 +1,330 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// catalogAttributeTypeUser is the attribute type of catalog attributes that reference incident.io users.
+const catalogAttributeTypeUser = "User"
+
+// catalogTypes lists catalog types once per sync for the team, catalog type and catalog entry
+// builders. The team and catalog type builders call refresh when they start listing, and the types
+// are read again once either of them starts listing a second time.
+type catalogTypes struct {
+	client *client.APIClient
+
+	mu      sync.Mutex
+	types   []client.CatalogType
+	readers syncReaders
+}
+
+// newCatalogTypes creates an empty list of catalog types, filled on first use.
+func newCatalogTypes(c *client.APIClient) *catalogTypes {
+	return &catalogTypes{client: c}
+}
+
+// refresh is called by a builder when it starts listing for a sync, and drops the catalog types
+// read by the previous sync.
+func (c *catalogTypes) refresh(reader string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.readers.start(reader) {
+		c.types = nil
+	}
+}
+
+// list returns every catalog type, fetching them from the API on first use.
+func (c *catalogTypes) list(ctx context.Context) ([]client.CatalogType, error) {
+	l := ctxzap.Extract(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.types != nil {
+		return c.types, nil
+	}
+
+	types, _, err := c.client.ListCatalogTypes(ctx)
+	if err != nil {
+		l.Error("Error fetching catalog types", zap.Error(err))
+		return nil, fmt.Errorf("error fetching catalog types: %w", err)
+	}
+
+	if types == nil {
+		types = []client.CatalogType{}
+	}
+
+	c.types = types
+
+	return c.types, nil
+}
+
+// get returns the catalog type with the given ID or name.
+func (c *catalogTypes) get(ctx context.Context, ref string) (client.CatalogType, bool, error) {
+	types, err := c.list(ctx)
+	if err != nil {
+		return client.CatalogType{}, false, err
+	}
+
+	catalogType, ok := findCatalogType(types, ref)
+
+	return catalogType, ok, nil
+}
+
+// userAttributes returns the attributes of a catalog type that reference users.
+func userAttributes(catalogType client.CatalogType) []client.CatalogTypeAttribute {
+	var attributes []client.CatalogTypeAttribute
+	for _, attribute := range catalogType.Schema.Attributes {
+		if attribute.Type == catalogAttributeTypeUser {
+			attributes = append(attributes, attribute)
+		}
+	}
+
+	return attributes
+}
+
+// catalogTypeBuilder syncs the configured catalog types. Their entries are synced as child resources.
+type catalogTypeBuilder struct {
+	resourceType *v2.ResourceType
+	catalogTypes *catalogTypes
+	refs         []string
+}
+
+// ResourceType returns the resource type associated with catalog types.
+func (o *catalogTypeBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return catalogTypeResourceType
+}
+
+// List retrieves the configured catalog types. Types missing from the tenant are skipped.
+func (o *catalogTypeBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	o.catalogTypes.refresh(catalogTypeResourceType.Id)
+
+	var resources []*v2.Resource
+	seenTypes := make(map[string]bool)
+	for _, ref := range o.refs {
+		catalogType, ok, err := o.catalogTypes.get(ctx, ref)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		if !ok {
+			l.Warn("Catalog type not found, skipping", zap.String("catalog_type", ref))
+			continue
+		}
+
+		if seenTypes[catalogType.ID] {
+			continue
+		}
+
+		seenTypes[catalogType.ID] = true
+
+		catalogTypeResource, err := resource.NewResource(
+			catalogType.Name,
+			catalogTypeResourceType,
+			catalogType.ID,
+			resource.WithParentResourceID(parentResourceID),
+			resource.WithDescription(catalogType.Description),
+			resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: catalogEntryResourceType.Id}),
+		)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error creating catalog type resource: %w", err)
+		}
+
+		resources = append(resources, catalogTypeResource)
+	}
+
+	return resources, "", nil, nil
+}
+
+// Entitlements always returns an empty slice for catalog types.
+func (o *catalogTypeBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for catalog types.
+func (o *catalogTypeBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// catalogEntryBuilder syncs the entries of a catalog type, turning each user attribute
+// of the type into an entitlement.
+type catalogEntryBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.APIClient
+	catalogTypes *catalogTypes
+}
+
+// ResourceType returns the resource type associated with catalog entries.
+func (o *catalogEntryBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return catalogEntryResourceType
+}
+
+// List retrieves the entries of the parent catalog type.
+func (o *catalogEntryBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	bag, pageToken, err := getToken(pToken, catalogEntryResourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	entries, nextPageToken, annos, err := o.client.ListCatalogEntries(ctx, parentResourceID.Resource, client.PageOptions{
+		After:    pageToken,
+		PageSize: pToken.Size,
+	})
+	if err != nil {
+		l.Error("Error fetching catalog entries", zap.Error(err), zap.String("catalog_type_id", parentResourceID.Resource))
+		return nil, "", nil, fmt.Errorf("error fetching catalog entries: %w", err)
+	}
+
+	var resources []*v2.Resource
+	for _, entry := range entries {
+		entryResource, err := resource.NewResource(
+			entry.Name,
+			catalogEntryResourceType,
+			entry.ID,
+			resource.WithParentResourceID(parentResourceID),
+		)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error creating catalog entry resource: %w", err)
+		}
+
+		resources = append(resources, entryResource)
+	}
+
+	err = bag.Next(nextPageToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPageToken, err = bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return resources, nextPageToken, annos, nil
+}
+
+// Entitlements returns a permission entitlement for each user attribute of the entry's catalog type.
+func (o *catalogEntryBuilder) Entitlements(ctx context.Context, entryResource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	catalogType, err := o.entryCatalogType(ctx, entryResource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var entitlements []*v2.Entitlement
+	for _, attribute := range userAttributes(catalogType) {
+		entitlements = append(entitlements, entitlement.NewPermissionEntitlement(
+			entryResource,
+			attribute.ID,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s %s", entryResource.DisplayName, attribute.Name)),
+			entitlement.WithDescription(fmt.Sprintf("%s of the %s %s", attribute.Name, entryResource.DisplayName, catalogType.Name)),
+		))
+	}
+
+	return entitlements, "", nil, nil
+}
+
+// Grants returns a grant for every user referenced by a user attribute of the entry.
+func (o *catalogEntryBuilder) Grants(ctx context.Context, entryResource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	catalogType, err := o.entryCatalogType(ctx, entryResource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	entry, annos, err := o.client.GetCatalogEntry(ctx, entryResource.Id.Resource)
+	if err != nil {
+		l.Error("Error fetching catalog entry", zap.Error(err), zap.String("catalog_entry_id", entryResource.Id.Resource))
+		return nil, "", nil, fmt.Errorf("error fetching catalog entry %s: %w", entryResource.Id.Resource, err)
+	}
+
+	var grants []*v2.Grant
+	for _, attribute := range userAttributes(catalogType) {
+		seenUsers := make(map[string]bool)
+		for _, userID := range entry.AttributeValues[attribute.ID].Literals() {
+			if seenUsers[userID] {
+				continue
+			}
+
+			seenUsers[userID] = true
+
+			principalID, err := resource.NewResourceID(userResourceType, userID)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("failed to create resource ID for user: %s", userID)
+			}
+
+			grants = append(grants, grant.NewGrant(entryResource, attribute.ID, principalID))
+		}
+	}
+
+	return grants, "", annos, nil
+}
+
+// entryCatalogType returns the catalog type of an entry, read from the entry's parent resource
+// or, when the parent is unknown, from the entry itself.
+func (o *catalogEntryBuilder) entryCatalogType(ctx context.Context, entryResource *v2.Resource) (client.CatalogType, error) {
+	catalogTypeID := ""
+	if entryResource.ParentResourceId != nil {
+		catalogTypeID = entryResource.ParentResourceId.Resource
+	}
+
+	if catalogTypeID == "" {
+		entry, _, err := o.client.GetCatalogEntry(ctx, entryResource.Id.Resource)
+		if err != nil {
+			return client.CatalogType{}, fmt.Errorf("error fetching catalog entry %s: %w", entryResource.Id.Resource, err)
+		}
+
+		catalogTypeID = entry.CatalogTypeID
+	}
+
+	catalogType, ok, err := o.catalogTypes.get(ctx, catalogTypeID)
+	if err != nil {
+		return client.CatalogType{}, err
+	}
+
+	if !ok {
+		return client.CatalogType{}, fmt.Errorf("catalog type %s not found", catalogTypeID)
+	}
+
+	return catalogType, nil
+}
+
+// NewCatalogTypeBuilder initializes a builder syncing the catalog types matching refs (IDs or names).
+func NewCatalogTypeBuilder(types *catalogTypes, refs []string) *catalogTypeBuilder {
+	return &catalogTypeBuilder{
+		resourceType: catalogTypeResourceType,
+		catalogTypes: types,
+		refs:         refs,
+	}
+}
+
+// NewCatalogEntryBuilder initializes a new catalog entry builder.
+func NewCatalogEntryBuilder(c *client.APIClient, types *catalogTypes) *catalogEntryBuilder {
+	return &catalogEntryBuilder{
+		resourceType: catalogEntryResourceType,
+		client:       c,
+		catalogTypes: types,
+	}
+}
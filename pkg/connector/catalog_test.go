@@ -0,0 +1,95 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+const (
+	serviceCatalogTypeID = "01HZ3T8GQ4M2B5Y6R7K9W0SERV"
+	checkoutEntryID      = "01HZ3TC7MW2F9H4Q8R1T6CHKOT"
+)
+
+var serviceCatalogRoutes = map[string]string{
+	"/v2/catalog_types":                      "catalogTypesMock.json",
+	"/v2/catalog_entries/" + checkoutEntryID: "catalogEntryServiceMock.json",
+}
+
+func checkoutEntryResource() *v2.Resource {
+	return &v2.Resource{
+		Id:               &v2.ResourceId{ResourceType: catalogEntryResourceType.Id, Resource: checkoutEntryID},
+		ParentResourceId: &v2.ResourceId{ResourceType: catalogTypeResourceType.Id, Resource: serviceCatalogTypeID},
+		DisplayName:      "Checkout",
+	}
+}
+
+func TestCatalogTypeBuilderList(t *testing.T) {
+	b := NewCatalogTypeBuilder(newCatalogTypes(test.NewRoutingTestClient(serviceCatalogRoutes)), []string{"service", "Missing", serviceCatalogTypeID})
+
+	resources, _, _, err := b.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resources) != 1 {
+		t.Fatalf("Expected 1 catalog type, got %d", len(resources))
+	}
+
+	if resources[0].Id.Resource != serviceCatalogTypeID {
+		t.Errorf("Expected catalog type %s, got %s", serviceCatalogTypeID, resources[0].Id.Resource)
+	}
+}
+
+func TestCatalogEntryBuilderEntitlements(t *testing.T) {
+	c := test.NewRoutingTestClient(serviceCatalogRoutes)
+	b := NewCatalogEntryBuilder(c, newCatalogTypes(c))
+
+	entitlements, _, _, err := b.Entitlements(context.Background(), checkoutEntryResource(), &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"catalog_entry:" + checkoutEntryID + ":01HZ3T8GQ4M2B5Y6R7K9W0OWNR": "Checkout Owner",
+		"catalog_entry:" + checkoutEntryID + ":01HZ3T8GQ4M2B5Y6R7K9W0ONCL": "Checkout On-call lead",
+	}
+
+	if len(entitlements) != len(expected) {
+		t.Fatalf("Expected %d entitlements, got %d", len(expected), len(entitlements))
+	}
+
+	for _, ent := range entitlements {
+		if displayName, ok := expected[ent.Id]; !ok || displayName != ent.DisplayName {
+			t.Errorf("Unexpected entitlement %s (%s)", ent.Id, ent.DisplayName)
+		}
+	}
+}
+
+func TestCatalogEntryBuilderGrants(t *testing.T) {
+	c := test.NewRoutingTestClient(serviceCatalogRoutes)
+	b := NewCatalogEntryBuilder(c, newCatalogTypes(c))
+
+	grants, _, _, err := b.Grants(context.Background(), checkoutEntryResource(), &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		"catalog_entry:" + checkoutEntryID + ":01HZ3T8GQ4M2B5Y6R7K9W0OWNR": "01JPWQNM50YGKQYFJYW61BBPD7",
+		"catalog_entry:" + checkoutEntryID + ":01HZ3T8GQ4M2B5Y6R7K9W0ONCL": "01JPWQP39ZE3X1NRHC3PJAWZVQ",
+	}
+
+	if len(grants) != len(expected) {
+		t.Fatalf("Expected %d grants, got %d", len(expected), len(grants))
+	}
+
+	for _, g := range grants {
+		if userID, ok := expected[g.Entitlement.Id]; !ok || userID != g.Principal.Id.Resource {
+			t.Errorf("Unexpected grant of %s to %s", g.Entitlement.Id, g.Principal.Id.Resource)
+		}
+	}
+}
@@ -2,24 +2,215 @@ package connector
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/conductorone/baton-incident-io/pkg/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
 	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
 )
 
+const (
+	defaultTeamMembersAttribute = "Members"
+
+	// defaultOverrideDuration is how long the schedule overrides created by provisioning last.
+	defaultOverrideDuration = 4 * time.Hour
+
+	// defaultCoverageGapWindow is how far ahead unassigned schedule shifts are looked for.
+	defaultCoverageGapWindow = 7 * 24 * time.Hour
+)
+
+// requiredScopes lists the API key roles needed to sync each resource type. Every resource type has
+// an entry, empty when any API key will do.
+var requiredScopes = map[string][]string{
+	userResourceType.Id:           {"viewer"},
+	roleResourceType.Id:           {"viewer"},
+	scheduleResourceType.Id:       {"schedules_reader"},
+	rotationResourceType.Id:       {"schedules_reader"},
+	escalationPathResourceType.Id: {"schedules_reader"},
+	teamResourceType.Id:           {"catalog_viewer"},
+	catalogTypeResourceType.Id:    {"catalog_viewer"},
+	catalogEntryResourceType.Id:   {"catalog_viewer"},
+	incidentResourceType.Id:       {"viewer"},
+	incidentRoleResourceType.Id:   {"viewer"},
+	workflowResourceType.Id:       {"viewer", "schedules_reader"},
+	apiKeyResourceType.Id:         {},
+	// SCIM groups are read with the SCIM token, which Validate checks separately.
+	scimGroupResourceType.Id: {},
+}
+
+// provisioningScopes lists the API key roles needed on top of requiredScopes to provision each
+// resource type. Accounts and SCIM group memberships are provisioned with the SCIM token instead.
+var provisioningScopes = map[string][]string{
+	roleResourceType.Id:     {"manage_settings"},
+	scheduleResourceType.Id: {"schedules_editor"},
+	rotationResourceType.Id: {"schedules_editor"},
+}
+
 type Connector struct {
-	apiClient *client.APIClient
+	apiClient            *client.APIClient
+	scimToken            string
+	teamCatalogType      string
+	teamMembersAttribute string
+	catalogTypes         []string
+	overrideDuration     time.Duration
+	coverageGapWindow    time.Duration
+	syncIncidents        bool
+	incidentFilter       client.IncidentFilter
+	fallbackBaseRole     string
+	provisioning         bool
+
+	baseURL      string
+	caBundlePath string
+	proxyURL     string
+}
+
+// Option configures optional behaviour of the connector.
+type Option func(*Connector)
+
+// WithSCIMToken sets the SCIM token used to create and delete user accounts.
+func WithSCIMToken(scimToken string) Option {
+	return func(d *Connector) {
+		d.scimToken = scimToken
+	}
+}
+
+// WithProvisioning tells the connector whether provisioning is enabled, so that Validate checks the
+// API key can make the changes provisioning makes.
+func WithProvisioning(provisioning bool) Option {
+	return func(d *Connector) {
+		d.provisioning = provisioning
+	}
+}
+
+// WithTeamCatalog sets the catalog type (ID or name) that teams are read from and the attribute
+// (ID or name) of that type listing the team members. Teams are only synced once a catalog type is set.
+func WithTeamCatalog(catalogType, membersAttribute string) Option {
+	return func(d *Connector) {
+		d.teamCatalogType = catalogType
+
+		if membersAttribute != "" {
+			d.teamMembersAttribute = membersAttribute
+		}
+	}
+}
+
+// WithCatalogTypes sets the catalog types (IDs or names) whose entries are synced,
+// with every user attribute of the type turned into an entitlement.
+func WithCatalogTypes(catalogTypes []string) Option {
+	return func(d *Connector) {
+		d.catalogTypes = catalogTypes
+	}
+}
+
+// WithOverrideDuration sets how long the schedule overrides created by granting a schedule's
+// Override entitlement last.
+func WithOverrideDuration(overrideDuration time.Duration) Option {
+	return func(d *Connector) {
+		if overrideDuration > 0 {
+			d.overrideDuration = overrideDuration
+		}
+	}
+}
+
+// WithCoverageGapWindow sets how far ahead unassigned schedule shifts are looked for and reported
+// on the schedule profiles. A zero window turns the look-ahead off.
+func WithCoverageGapWindow(coverageGapWindow time.Duration) Option {
+	return func(d *Connector) {
+		if coverageGapWindow >= 0 {
+			d.coverageGapWindow = coverageGapWindow
+		}
+	}
+}
+
+// WithIncidents sets whether incidents are synced. They are left out unless turned on, as most
+// installs do not review incident access.
+func WithIncidents(syncIncidents bool) Option {
+	return func(d *Connector) {
+		d.syncIncidents = syncIncidents
+	}
+}
+
+// WithIncidentFilter limits the incidents synced to those whose status is in one of statusCategories
+// and that were created on or after createdAfter. Empty values do not filter.
+func WithIncidentFilter(statusCategories []string, createdAfter time.Time) Option {
+	return func(d *Connector) {
+		d.incidentFilter = client.IncidentFilter{
+			StatusCategories: statusCategories,
+			CreatedAfter:     createdAfter,
+		}
+	}
+}
+
+// WithFallbackBaseRole sets the ID of the base role users are moved to when their base role is
+// revoked. Without it, base roles cannot be revoked.
+func WithFallbackBaseRole(roleID string) Option {
+	return func(d *Connector) {
+		d.fallbackBaseRole = roleID
+	}
+}
+
+// WithBaseURL overrides the incident.io API base URL.
+func WithBaseURL(baseURL string) Option {
+	return func(d *Connector) {
+		d.baseURL = baseURL
+	}
+}
+
+// WithTransport sets a PEM CA bundle trusted in addition to the system roots
+// and a proxy that API requests are sent through. Both are optional.
+func WithTransport(caBundlePath, proxyURL string) Option {
+	return func(d *Connector) {
+		d.caBundlePath = caBundlePath
+		d.proxyURL = proxyURL
+	}
 }
 
 // ResourceSyncers returns a ResourceSyncer for each resource type that should be synced from the upstream service.
 func (d *Connector) ResourceSyncers(ctx context.Context) []connectorbuilder.ResourceSyncer {
-	return []connectorbuilder.ResourceSyncer{
-		NewUserBuilder(d.apiClient),
-		NewScheduleBuilder(d.apiClient),
+	users := newUserIndex(d.apiClient)
+	catalogTypes := newCatalogTypes(d.apiClient)
+
+	syncers := []connectorbuilder.ResourceSyncer{
+		NewUserBuilder(d.apiClient, users),
+		NewRoleBuilder(d.apiClient, users, d.fallbackBaseRole),
+		NewScheduleBuilder(d.apiClient, d.overrideDuration, d.coverageGapWindow),
+		NewRotationBuilder(d.apiClient),
+		NewEscalationPathBuilder(d.apiClient),
+		NewIncidentRoleBuilder(d.apiClient),
+		NewAPIKeyBuilder(d.apiClient),
+		NewWorkflowBuilder(d.apiClient, users),
+	}
+
+	// Teams live in a catalog type of the organisation's own making, and need the catalog_viewer role.
+	if d.teamCatalogType != "" {
+		syncers = append(syncers, NewTeamBuilder(d.apiClient, catalogTypes, d.teamCatalogType, d.teamMembersAttribute))
+	}
+
+	// Incidents are opt-in, so that their scopes are only needed by installs that sync them.
+	if d.syncIncidents {
+		syncers = append(syncers, NewIncidentBuilder(d.apiClient, d.incidentFilter))
+	}
+
+	// SCIM groups are only readable with a SCIM token, which API keys cannot stand in for.
+	if d.scimToken != "" {
+		syncers = append(syncers, NewSCIMGroupBuilder(d.apiClient))
 	}
+
+	if len(d.catalogTypes) > 0 {
+		syncers = append(syncers,
+			NewCatalogTypeBuilder(catalogTypes, d.catalogTypes),
+			NewCatalogEntryBuilder(d.apiClient, catalogTypes),
+		)
+	}
+
+	return syncers
 }
 
 // Asset takes an input AssetRef and attempts to fetch it using the connector's authenticated http client
@@ -32,19 +223,111 @@ func (d *Connector) Asset(ctx context.Context, asset *v2.AssetRef) (string, io.R
 func (d *Connector) Metadata(ctx context.Context) (*v2.ConnectorMetadata, error) {
 	return &v2.ConnectorMetadata{
 		DisplayName: "Incidents.io connector",
-		Description: "sync users and schedules from incidents.io",
+		Description: "sync users, roles, schedules, escalation paths, teams, catalog entries, incidents, workflows and SCIM groups from incident.io",
+		AccountCreationSchema: &v2.ConnectorAccountCreationSchema{
+			FieldMap: map[string]*v2.ConnectorAccountCreationSchema_Field{
+				"email": {
+					DisplayName: "Email",
+					Required:    true,
+					Description: "The email address the user signs in to incident.io with",
+					Placeholder: "jane.doe@example.com",
+					Order:       1,
+					Field:       &v2.ConnectorAccountCreationSchema_Field_StringField{StringField: &v2.ConnectorAccountCreationSchema_StringField{}},
+				},
+				"name": {
+					DisplayName: "Name",
+					Description: "The full name of the user",
+					Placeholder: "Jane Doe",
+					Order:       2,
+					Field:       &v2.ConnectorAccountCreationSchema_Field_StringField{StringField: &v2.ConnectorAccountCreationSchema_StringField{}},
+				},
+				"base_role": {
+					DisplayName: "Base role",
+					Description: "The ID, slug or name of the base role to assign, e.g. viewer or user. The organisation default is used when empty",
+					Placeholder: "user",
+					Order:       3,
+					Field:       &v2.ConnectorAccountCreationSchema_Field_StringField{StringField: &v2.ConnectorAccountCreationSchema_StringField{}},
+				},
+			},
+		},
 	}, nil
 }
 
 // Validate is called to ensure that the connector is properly configured. It should exercise any API credentials
 // to be sure that they are valid.
 func (d *Connector) Validate(ctx context.Context) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	identity, _, err := d.apiClient.GetIdentity(ctx)
+	if err != nil {
+		l.Error("error validating API key", zap.Error(err))
+		return nil, fmt.Errorf("incident.io: failed to validate API key: %w", err)
+	}
+
+	granted := make(map[string]bool, len(identity.Roles))
+	for _, role := range identity.Roles {
+		granted[role] = true
+	}
+
+	var missing []string
+	for _, syncer := range d.ResourceSyncers(ctx) {
+		resourceType := syncer.ResourceType(ctx)
+		for _, scope := range requiredScopes[resourceType.Id] {
+			if !granted[scope] {
+				missing = append(missing, fmt.Sprintf("%s (needed for %s)", scope, resourceType.DisplayName))
+			}
+		}
+
+		if !d.provisioning {
+			continue
+		}
+
+		for _, scope := range provisioningScopes[resourceType.Id] {
+			if !granted[scope] {
+				missing = append(missing, fmt.Sprintf("%s (needed to provision %s)", scope, resourceType.DisplayName))
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("incident.io: API key %q is missing scopes: %s", identity.Name, strings.Join(missing, ", "))
+	}
+
+	if d.scimToken != "" {
+		// Reading a single group is enough to tell whether the SCIM API accepts the token.
+		_, _, _, err := d.apiClient.ListSCIMGroups(ctx, client.PageOptions{PageSize: 1})
+		if err != nil {
+			l.Error("error validating SCIM token", zap.Error(err))
+			return nil, fmt.Errorf("incident.io: failed to validate SCIM token: %w", err)
+		}
+	}
+
 	return nil, nil
 }
 
 // New returns a new instance of the connector.
-func New(ctx context.Context, accessToken string) (*Connector, error) {
-	apiClient := client.NewClient(accessToken, nil)
+func New(ctx context.Context, accessToken string, opts ...Option) (*Connector, error) {
+	d := &Connector{
+		teamMembersAttribute: defaultTeamMembersAttribute,
+		overrideDuration:     defaultOverrideDuration,
+		coverageGapWindow:    defaultCoverageGapWindow,
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	httpClient, err := client.NewHTTPClient(d.caBundlePath, d.proxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	d.apiClient = client.NewClient(
+		accessToken,
+		uhttp.NewBaseHttpClient(httpClient),
+		client.WithBaseURL(d.baseURL),
+		client.WithSCIMToken(d.scimToken),
+	)
 
-	return &Connector{apiClient: apiClient}, nil
+	return d, nil
 }
@@ -0,0 +1,167 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-incident-io/pkg/test"
+)
+
+func newValidateTestConnector(response *http.Response, catalogTypes []string) *Connector {
+	return &Connector{
+		apiClient:            test.NewTestClient(response, nil),
+		teamCatalogType:      "Team",
+		teamMembersAttribute: defaultTeamMembersAttribute,
+		catalogTypes:         catalogTypes,
+	}
+}
+
+func TestConnectorValidate(t *testing.T) {
+	body, err := test.ReadFile("identityMock.json")
+	if err != nil {
+		t.Fatalf("Error reading body: %s", err)
+	}
+
+	d := newValidateTestConnector(test.NewMockResponse(http.StatusOK, body), []string{"Service"})
+
+	_, err = d.Validate(context.Background())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+func TestConnectorValidate_MissingScopes(t *testing.T) {
+	body := `{"identity":{"name":"baton","roles":["viewer"]}}`
+
+	d := newValidateTestConnector(test.NewMockResponse(http.StatusOK, body), nil)
+
+	_, err := d.Validate(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error for missing scopes")
+	}
+
+	for _, scope := range []string{"schedules_reader", "catalog_viewer"} {
+		if !strings.Contains(err.Error(), scope) {
+			t.Errorf("Expected error to mention %s, got %v", scope, err)
+		}
+	}
+}
+
+func TestConnectorValidate_TeamsNotConfigured(t *testing.T) {
+	body := `{"identity":{"name":"baton","roles":["viewer","schedules_reader","global_access"]}}`
+
+	d := newValidateTestConnector(test.NewMockResponse(http.StatusOK, body), nil)
+	d.teamCatalogType = ""
+
+	_, err := d.Validate(context.Background())
+	if err != nil {
+		t.Fatalf("Expected catalog_viewer not to be needed without a team catalog, got %v", err)
+	}
+}
+
+func TestConnectorValidate_ProvisioningScopes(t *testing.T) {
+	body, err := test.ReadFile("identityMock.json")
+	if err != nil {
+		t.Fatalf("Error reading body: %s", err)
+	}
+
+	d := newValidateTestConnector(test.NewMockResponse(http.StatusOK, body), nil)
+	d.provisioning = true
+
+	_, err = d.Validate(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error for missing provisioning scopes")
+	}
+
+	for _, scope := range []string{"schedules_editor", "manage_settings"} {
+		if !strings.Contains(err.Error(), scope) {
+			t.Errorf("Expected error to mention %s, got %v", scope, err)
+		}
+	}
+}
+
+func TestConnectorValidate_SCIMToken(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{name: "valid token", statusCode: http.StatusOK},
+		{name: "rejected token", statusCode: http.StatusUnauthorized, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []test.Request
+			d := &Connector{
+				apiClient: test.NewRoutingTestClient(
+					map[string]string{"/v1/identity": "identityMock.json"},
+					test.WithHandler("GET /scim/v2/Groups", func(*http.Request, []byte) (int, string) {
+						return tc.statusCode, `{"totalResults":0,"Resources":[]}`
+					}),
+					test.WithRecorder(&requests),
+					test.WithClientOptions(client.WithSCIMToken("scim")),
+				),
+				scimToken: "scim",
+			}
+
+			_, err := d.Validate(context.Background())
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("Expected error: %t, got %v", tc.wantErr, err)
+			}
+
+			if last := requests[len(requests)-1]; last.Path != "/scim/v2/Groups" || last.Token != "scim" {
+				t.Errorf("Expected the SCIM token to be checked, got %s %s", last.Method, last.Path)
+			}
+		})
+	}
+}
+
+func TestRequiredScopes_CoverEveryResourceType(t *testing.T) {
+	d := &Connector{
+		teamCatalogType: "Team",
+		catalogTypes:    []string{"Service"},
+		scimToken:       "scim",
+		syncIncidents:   true,
+	}
+
+	for _, syncer := range d.ResourceSyncers(context.Background()) {
+		resourceType := syncer.ResourceType(context.Background())
+		if _, ok := requiredScopes[resourceType.Id]; !ok {
+			t.Errorf("Expected requiredScopes to have an entry for %s", resourceType.Id)
+		}
+	}
+}
+
+func TestConnectorValidate_Unauthorized(t *testing.T) {
+	body := `{"type":"authentication_error","status":401,"request_id":"abc","errors":[{"code":"unauthorized","message":"invalid API key"}]}`
+
+	d := newValidateTestConnector(test.NewMockResponse(http.StatusUnauthorized, body), nil)
+
+	_, err := d.Validate(context.Background())
+	if err == nil {
+		t.Fatal("Expected an error for an invalid API key")
+	}
+}
+
+func TestResourceSyncers_IncidentsOptIn(t *testing.T) {
+	hasIncidents := func(d *Connector) bool {
+		for _, syncer := range d.ResourceSyncers(context.Background()) {
+			if syncer.ResourceType(context.Background()).Id == incidentResourceType.Id {
+				return true
+			}
+		}
+		return false
+	}
+
+	if hasIncidents(&Connector{}) {
+		t.Error("Expected incidents not to be synced unless turned on")
+	}
+
+	if !hasIncidents(&Connector{syncIncidents: true}) {
+		t.Error("Expected incidents to be synced once turned on")
+	}
+}
@@ -0,0 +1,185 @@
+package connector
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIncidentClient_APIError(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statusCode int
+		body       string
+		code       codes.Code
+		requestID  string
+		message    string
+	}{
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"type":"authentication_error","status":401,"request_id":"req-401","errors":[{"code":"unauthorized","message":"Invalid API key"}]}`,
+			code:       codes.Unauthenticated,
+			requestID:  "req-401",
+			message:    "unauthorized: Invalid API key",
+		},
+		{
+			name:       "forbidden",
+			statusCode: http.StatusForbidden,
+			body:       `{"type":"authorization_error","status":403,"request_id":"req-403","errors":[{"code":"missing_scope","message":"API key lacks the viewer role"}]}`,
+			code:       codes.PermissionDenied,
+			requestID:  "req-403",
+			message:    "missing_scope: API key lacks the viewer role",
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			body:       `{"type":"not_found","status":404,"request_id":"req-404","errors":[{"code":"not_found","message":"Resource not found"}]}`,
+			code:       codes.NotFound,
+			requestID:  "req-404",
+			message:    "not_found: Resource not found",
+		},
+		{
+			name:       "validation error",
+			statusCode: http.StatusUnprocessableEntity,
+			body:       `{"type":"validation_error","status":422,"request_id":"req-422","errors":[{"code":"is_required","message":"must be set","source":{"field":"base_role_id"}}]}`,
+			code:       codes.InvalidArgument,
+			requestID:  "req-422",
+			message:    "is_required: must be set [field base_role_id]",
+		},
+		{
+			name:       "non JSON gateway error",
+			statusCode: http.StatusBadGateway,
+			body:       `<html>Bad Gateway</html>`,
+			code:       codes.Unavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			response := test.NewMockResponse(tc.statusCode, tc.body)
+			if strings.HasPrefix(tc.body, "<") {
+				response.Header.Set("Content-Type", "text/html")
+			}
+
+			testClient := test.NewTestClient(response, nil)
+
+			_, _, _, err := testClient.ListUsers(context.Background(), pageOptions)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			var apiErr *client.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("Expected an APIError, got %T: %v", err, err)
+			}
+
+			if apiErr.Status != tc.statusCode {
+				t.Errorf("Expected status %d, got %d", tc.statusCode, apiErr.Status)
+			}
+
+			if apiErr.RequestID != tc.requestID {
+				t.Errorf("Expected request ID %q, got %q", tc.requestID, apiErr.RequestID)
+			}
+
+			if code := status.Code(err); code != tc.code {
+				t.Errorf("Expected gRPC code %s, got %s", tc.code, code)
+			}
+
+			if tc.message != "" && !strings.Contains(err.Error(), tc.message) {
+				t.Errorf("Expected error to contain %q, got %q", tc.message, err.Error())
+			}
+
+			if tc.requestID != "" && !strings.Contains(err.Error(), tc.requestID) {
+				t.Errorf("Expected error to contain the request ID, got %q", err.Error())
+			}
+		})
+	}
+}
+
+func TestIncidentClient_APIError_RateLimited(t *testing.T) {
+	requests := 0
+	mockTransport := &test.MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			requests++
+			response := test.NewMockResponse(http.StatusTooManyRequests, `{"type":"rate_limit_exceeded","status":429,"request_id":"req-429"}`)
+			response.Header.Set("Retry-After", "0")
+			return response, nil
+		},
+	}
+
+	testClient := client.NewClient("test", uhttp.NewBaseHttpClient(&http.Client{Transport: mockTransport}))
+
+	_, _, _, err := testClient.ListUsers(context.Background(), pageOptions)
+	if code := status.Code(err); code != codes.Unavailable {
+		t.Errorf("Expected gRPC code %s, got %s", codes.Unavailable, code)
+	}
+}
+
+func newRateLimitedTransport(failures int, requests *int) *test.MockRoundTripper {
+	return &test.MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			*requests++
+
+			if *requests <= failures {
+				response := test.NewMockResponse(http.StatusTooManyRequests, `{"type":"rate_limited","status":429}`)
+				response.Header.Set("Retry-After", "0")
+				return response, nil
+			}
+
+			response := test.NewMockResponse(http.StatusOK, `{"users":[],"pagination_meta":{"page_size":10}}`)
+			response.Header.Set("X-Ratelimit-Limit", "1200")
+			response.Header.Set("X-Ratelimit-Remaining", "1199")
+			return response, nil
+		},
+	}
+}
+
+func TestIncidentClient_RetriesRateLimit(t *testing.T) {
+	requests := 0
+	httpClient := &http.Client{Transport: newRateLimitedTransport(2, &requests)}
+	testClient := client.NewClient("test", uhttp.NewBaseHttpClient(httpClient))
+
+	_, _, annos, err := testClient.ListUsers(context.Background(), pageOptions)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("Expected 3 requests, got %d", requests)
+	}
+
+	rateLimit := &v2.RateLimitDescription{}
+	ok, err := annos.Pick(rateLimit)
+	if err != nil || !ok {
+		t.Fatalf("Expected a rate limit annotation, got %v (%v)", ok, err)
+	}
+
+	if rateLimit.Limit != 1200 || rateLimit.Remaining != 1199 {
+		t.Errorf("Unexpected rate limit: %+v", rateLimit)
+	}
+}
+
+func TestIncidentClient_RateLimitRetriesBounded(t *testing.T) {
+	requests := 0
+	httpClient := &http.Client{Transport: newRateLimitedTransport(100, &requests)}
+	testClient := client.NewClient("test", uhttp.NewBaseHttpClient(httpClient))
+
+	_, _, _, err := testClient.ListUsers(context.Background(), pageOptions)
+	if err == nil {
+		t.Fatal("Expected an error once retries are exhausted")
+	}
+
+	if requests != 6 {
+		t.Errorf("Expected 6 requests, got %d", requests)
+	}
+}
@@ -0,0 +1,218 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	escalationPathNodeLevel  = "level"
+	escalationPathNodeIfElse = "if_else"
+
+	escalationPathTargetUser     = "user"
+	escalationPathTargetSchedule = "schedule"
+)
+
+// escalationPathBuilder handles resource type and client interactions
+// for managing escalation path resources.
+type escalationPathBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.APIClient
+}
+
+// ResourceType returns the resource type associated with escalation paths.
+func (o *escalationPathBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return escalationPathResourceType
+}
+
+// List retrieves a list of escalation path resources.
+func (o *escalationPathBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	bag, pageToken, err := getToken(pToken, escalationPathResourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	escalationPaths, nextPageToken, annos, err := o.client.ListEscalationPaths(ctx, client.PageOptions{
+		After:    pageToken,
+		PageSize: pToken.Size,
+	})
+	if err != nil {
+		l.Error("Error fetching escalation paths", zap.Error(err))
+		return nil, "", nil, fmt.Errorf("error fetching escalation paths: %w", err)
+	}
+
+	var resources []*v2.Resource
+	for _, escalationPath := range escalationPaths {
+		escalationPathResource, err := resource.NewResource(
+			escalationPath.Name,
+			escalationPathResourceType,
+			escalationPath.ID,
+			resource.WithParentResourceID(parentResourceID),
+		)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error creating escalation path resource: %w", err)
+		}
+
+		resources = append(resources, escalationPathResource)
+	}
+
+	err = bag.Next(nextPageToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPageToken, err = bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return resources, nextPageToken, annos, nil
+}
+
+// Entitlements returns one entitlement per escalation level of the path.
+func (o *escalationPathBuilder) Entitlements(ctx context.Context, escalationPathResource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	escalationPath, _, err := o.client.GetEscalationPath(ctx, escalationPathResource.Id.Resource)
+	if err != nil {
+		l.Error("Error fetching escalation path", zap.Error(err), zap.String("escalation_path_id", escalationPathResource.Id.Resource))
+		return nil, "", nil, fmt.Errorf("error fetching escalation path %s: %w", escalationPathResource.Id.Resource, err)
+	}
+
+	var entitlements []*v2.Entitlement
+	for index := range escalationLevels(escalationPath.Path) {
+		level := index + 1
+		entitlements = append(entitlements, entitlement.NewPermissionEntitlement(
+			escalationPathResource,
+			escalationLevelEntitlement(level),
+			entitlement.WithGrantableTo(userResourceType, scheduleResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Level %d", escalationPathResource.DisplayName, level)),
+			entitlement.WithDescription(fmt.Sprintf("Paged at level %d of the %s escalation path", level, escalationPathResource.DisplayName)),
+		))
+	}
+
+	return entitlements, "", nil, nil
+}
+
+// Grants returns a grant for each target of each escalation level. Users are granted directly,
+// schedules are granted and expanded to the users on their On_Call and Member entitlements.
+func (o *escalationPathBuilder) Grants(ctx context.Context, escalationPathResource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	escalationPath, annos, err := o.client.GetEscalationPath(ctx, escalationPathResource.Id.Resource)
+	if err != nil {
+		l.Error("Error fetching escalation path", zap.Error(err), zap.String("escalation_path_id", escalationPathResource.Id.Resource))
+		return nil, "", nil, fmt.Errorf("error fetching escalation path %s: %w", escalationPathResource.Id.Resource, err)
+	}
+
+	var grants []*v2.Grant
+	for index, targets := range escalationLevels(escalationPath.Path) {
+		entitlementName := escalationLevelEntitlement(index + 1)
+		seenTargets := make(map[string]bool)
+
+		for _, target := range targets {
+			if seenTargets[target.Type+":"+target.ID] {
+				continue
+			}
+
+			seenTargets[target.Type+":"+target.ID] = true
+
+			switch target.Type {
+			case escalationPathTargetUser:
+				principalID, err := resource.NewResourceID(userResourceType, target.ID)
+				if err != nil {
+					return nil, "", nil, fmt.Errorf("failed to create resource ID for user: %s", target.ID)
+				}
+
+				grants = append(grants, grant.NewGrant(escalationPathResource, entitlementName, principalID))
+			case escalationPathTargetSchedule:
+				principalID, err := resource.NewResourceID(scheduleResourceType, target.ID)
+				if err != nil {
+					return nil, "", nil, fmt.Errorf("failed to create resource ID for schedule: %s", target.ID)
+				}
+
+				grants = append(grants, grant.NewGrant(
+					escalationPathResource,
+					entitlementName,
+					principalID,
+					grant.WithAnnotation(&v2.GrantExpandable{
+						EntitlementIds: []string{
+							fmt.Sprintf("%s:%s:%s", scheduleResourceType.Id, target.ID, scheduleOnCallEntitlement),
+							fmt.Sprintf("%s:%s:%s", scheduleResourceType.Id, target.ID, scheduleMemberEntitlement),
+						},
+					}),
+				))
+			default:
+				l.Debug("Skipping unsupported escalation target", zap.String("type", target.Type), zap.String("id", target.ID))
+			}
+		}
+	}
+
+	return grants, "", annos, nil
+}
+
+// escalationLevels returns the targets paged at each level of an escalation path. Levels are
+// numbered by how many levels come before them, and both branches of an if/else node continue
+// from the level the node is at: the second level of either branch is the path's next level.
+func escalationLevels(path []client.EscalationPathNode) [][]client.EscalationPathTarget {
+	levels, _ := appendEscalationLevels(nil, path, 0)
+
+	return levels
+}
+
+// appendEscalationLevels adds the targets of the levels of path to levels, starting at depth, and
+// returns the depth reached by the longest branch.
+func appendEscalationLevels(levels [][]client.EscalationPathTarget, path []client.EscalationPathNode, depth int) ([][]client.EscalationPathTarget, int) {
+	for _, node := range path {
+		switch node.Type {
+		case escalationPathNodeLevel:
+			if node.Level == nil {
+				continue
+			}
+
+			if depth == len(levels) {
+				levels = append(levels, nil)
+			}
+
+			levels[depth] = append(levels[depth], node.Level.Targets...)
+			depth++
+		case escalationPathNodeIfElse:
+			if node.IfElse == nil {
+				continue
+			}
+
+			var thenDepth, elseDepth int
+			levels, thenDepth = appendEscalationLevels(levels, node.IfElse.ThenPath, depth)
+			levels, elseDepth = appendEscalationLevels(levels, node.IfElse.ElsePath, depth)
+			depth = max(thenDepth, elseDepth)
+		}
+	}
+
+	return levels, depth
+}
+
+// escalationLevelEntitlement returns the entitlement name for a 1-based escalation level.
+func escalationLevelEntitlement(level int) string {
+	return "level_" + strconv.Itoa(level)
+}
+
+// NewEscalationPathBuilder initializes a new escalation path builder.
+func NewEscalationPathBuilder(c *client.APIClient) *escalationPathBuilder {
+	return &escalationPathBuilder{
+		resourceType: escalationPathResourceType,
+		client:       c,
+	}
+}
@@ -0,0 +1,151 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+const escalationPathID = "01JR0Q3XAD9K5Y4W8N2M6P1EPA"
+
+func newEscalationPathTestBuilder(t *testing.T) *escalationPathBuilder {
+	body, err := test.ReadFile("escalationPathMock.json")
+	if err != nil {
+		t.Fatalf("Error reading body: %s", err)
+	}
+
+	return NewEscalationPathBuilder(test.NewTestClient(test.NewMockResponse(http.StatusOK, body), nil))
+}
+
+func escalationPathResourceFor(id string) *v2.Resource {
+	return &v2.Resource{
+		Id:          &v2.ResourceId{ResourceType: escalationPathResourceType.Id, Resource: id},
+		DisplayName: "Payments",
+	}
+}
+
+func TestEscalationPathBuilderEntitlements(t *testing.T) {
+	e := newEscalationPathTestBuilder(t)
+
+	entitlements, _, _, err := e.Entitlements(context.Background(), escalationPathResourceFor(escalationPathID), &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{
+		"escalation_path:" + escalationPathID + ":level_1",
+		"escalation_path:" + escalationPathID + ":level_2",
+	}
+
+	if len(entitlements) != len(expected) {
+		t.Fatalf("Expected %d entitlements, got %d", len(expected), len(entitlements))
+	}
+
+	for i, ent := range entitlements {
+		if ent.Id != expected[i] {
+			t.Errorf("Expected entitlement %s, got %s", expected[i], ent.Id)
+		}
+	}
+}
+
+func TestEscalationPathBuilderGrants(t *testing.T) {
+	e := newEscalationPathTestBuilder(t)
+
+	grants, _, _, err := e.Grants(context.Background(), escalationPathResourceFor(escalationPathID), &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(grants) != 3 {
+		t.Fatalf("Expected 3 grants, got %d", len(grants))
+	}
+
+	level1 := "escalation_path:" + escalationPathID + ":level_1"
+	level2 := "escalation_path:" + escalationPathID + ":level_2"
+
+	testCases := []struct {
+		entitlementID string
+		principalType string
+		principalID   string
+		expandable    bool
+	}{
+		{level1, scheduleResourceType.Id, "01JQ77YN7BRVRA81T9STQ41HB2", true},
+		{level1, userResourceType.Id, "01JPWQNM50YGKQYFJYW61BBPD7", false},
+		{level2, userResourceType.Id, "01JPWQP39ZE3X1NRHC3PJAWZVQ", false},
+	}
+
+	for i, tc := range testCases {
+		g := grants[i]
+
+		if g.Entitlement.Id != tc.entitlementID {
+			t.Errorf("Expected entitlement %s, got %s", tc.entitlementID, g.Entitlement.Id)
+		}
+
+		if g.Principal.Id.ResourceType != tc.principalType || g.Principal.Id.Resource != tc.principalID {
+			t.Errorf("Expected principal %s:%s, got %s:%s", tc.principalType, tc.principalID, g.Principal.Id.ResourceType, g.Principal.Id.Resource)
+		}
+
+		expandable := &v2.GrantExpandable{}
+		annos := annotations.Annotations(g.Annotations)
+		ok, err := annos.Pick(expandable)
+		if err != nil {
+			t.Fatalf("Error reading annotations: %s", err)
+		}
+
+		if ok != tc.expandable {
+			t.Errorf("Expected expandable=%t for %s, got %t", tc.expandable, tc.principalID, ok)
+		}
+
+		if ok && len(expandable.EntitlementIds) != 2 {
+			t.Errorf("Expected schedule grant to expand On_Call and Member, got %v", expandable.EntitlementIds)
+		}
+	}
+}
+
+func TestEscalationPathBuilderGrants_Branches(t *testing.T) {
+	const branchesEscalationPathID = "01JR0Q3XAD9K5Y4W8N2M6P2EPB"
+
+	e := NewEscalationPathBuilder(test.NewRoutingTestClient(map[string]string{
+		"/v2/escalation_paths/" + branchesEscalationPathID: "escalationPathBranchesMock.json",
+	}))
+
+	escalationPathResource := escalationPathResourceFor(branchesEscalationPathID)
+
+	entitlements, _, _, err := e.Entitlements(context.Background(), escalationPathResource, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(entitlements) != 3 {
+		t.Fatalf("Expected 3 levels, the longest branch being 3 levels deep, got %d", len(entitlements))
+	}
+
+	grants, _, _, err := e.Grants(context.Background(), escalationPathResource, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The first level of each branch is level 2 of the path.
+	expected := []string{
+		"level_1 " + "01JPWQNM50YGKQYFJYW61BBPD7",
+		"level_2 " + "01JPWQP39ZE3X1NRHC3PJAWZVQ",
+		"level_2 " + "01JQ77YN7BRVRA81T9STQ41HB2",
+		"level_3 " + "01JPWQP39ZE3X1NRHC3PJAWZVQ",
+	}
+
+	var actual []string
+	for _, g := range grants {
+		actual = append(actual, strings.TrimPrefix(g.Entitlement.Id, "escalation_path:"+branchesEscalationPathID+":")+" "+g.Principal.Id.Resource)
+	}
+
+	if !slices.Equal(actual, expected) {
+		t.Errorf("Unexpected grants: got %v, want %v", actual, expected)
+	}
+}
@@ -1,6 +1,10 @@
 package connector
 
 import (
+	"errors"
+	"strings"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 )
@@ -34,3 +38,52 @@ func unmarshalSkipToken(token *pagination.Token) (*pagination.Bag, string, error
 
 	return b, current.Token, nil
 }
+
+// findCatalogType returns the catalog type whose ID, name or type name matches ref.
+func findCatalogType(catalogTypes []client.CatalogType, ref string) (client.CatalogType, bool) {
+	for _, catalogType := range catalogTypes {
+		if catalogType.ID == ref || catalogType.TypeName == ref || strings.EqualFold(catalogType.Name, ref) {
+			return catalogType, true
+		}
+	}
+
+	return client.CatalogType{}, false
+}
+
+// findCatalogAttribute returns the attribute of a catalog type whose ID or name matches ref.
+func findCatalogAttribute(catalogType client.CatalogType, ref string) (client.CatalogTypeAttribute, bool) {
+	for _, attribute := range catalogType.Schema.Attributes {
+		if attribute.ID == ref || strings.EqualFold(attribute.Name, ref) {
+			return attribute, true
+		}
+	}
+
+	return client.CatalogTypeAttribute{}, false
+}
+
+// syncReaders tells when a new sync begins from the builders sharing data read once per sync. Each
+// builder calls start when it begins listing; a builder beginning twice means a new sync has begun.
+type syncReaders map[string]bool
+
+// start records that reader began listing and reports whether it already had, in which case the
+// shared data should be read again.
+func (r *syncReaders) start(reader string) bool {
+	if (*r)[reader] {
+		*r = syncReaders{reader: true}
+		return true
+	}
+
+	if *r == nil {
+		*r = syncReaders{}
+	}
+
+	(*r)[reader] = true
+
+	return false
+}
+
+// hasAPIStatus reports whether err is an incident.io API error with the given HTTP status.
+func hasAPIStatus(err error, status int) bool {
+	var apiErr *client.APIError
+	return errors.As(err, &apiErr) && apiErr.Status == status
+}
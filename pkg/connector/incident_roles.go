@@ -0,0 +1,92 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// incidentRoleBuilder syncs the incident roles defined in incident.io, so that role definitions can
+// be reviewed. Who holds them is reported on the incidents themselves.
+type incidentRoleBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.APIClient
+}
+
+// ResourceType returns the resource type associated with incident roles.
+func (o *incidentRoleBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return incidentRoleResourceType
+}
+
+// List retrieves every incident role. The endpoint is not paginated.
+func (o *incidentRoleBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	roles, annos, err := o.client.ListIncidentRoles(ctx)
+	if err != nil {
+		l.Error("Error fetching incident roles", zap.Error(err))
+		return nil, "", nil, fmt.Errorf("error fetching incident roles: %w", err)
+	}
+
+	var resources []*v2.Resource
+	for _, role := range roles {
+		profile := map[string]interface{}{
+			"incident_role_id": role.ID,
+			"description":      role.Description,
+			"instructions":     role.Instructions,
+			"shortform":        role.Shortform,
+			"role_type":        role.RoleType,
+			"required":         role.Required,
+		}
+
+		if role.CreatedAt != nil {
+			profile["created_at"] = role.CreatedAt.Format(time.RFC3339)
+		}
+
+		if role.UpdatedAt != nil {
+			profile["updated_at"] = role.UpdatedAt.Format(time.RFC3339)
+		}
+
+		roleResource, err := resource.NewRoleResource(
+			role.Name,
+			incidentRoleResourceType,
+			role.ID,
+			[]resource.RoleTraitOption{resource.WithRoleProfile(profile)},
+			resource.WithParentResourceID(parentResourceID),
+			resource.WithDescription(role.Description),
+		)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error creating incident role resource: %w", err)
+		}
+
+		resources = append(resources, roleResource)
+	}
+
+	return resources, "", annos, nil
+}
+
+// Entitlements always returns an empty slice for incident roles.
+func (o *incidentRoleBuilder) Entitlements(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// Grants always returns an empty slice for incident roles.
+func (o *incidentRoleBuilder) Grants(_ context.Context, _ *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	return nil, "", nil, nil
+}
+
+// NewIncidentRoleBuilder initializes a new incident role builder.
+func NewIncidentRoleBuilder(c *client.APIClient) *incidentRoleBuilder {
+	return &incidentRoleBuilder{
+		resourceType: incidentRoleResourceType,
+		client:       c,
+	}
+}
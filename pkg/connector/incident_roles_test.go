@@ -0,0 +1,57 @@
+package connector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+func TestIncidentRoleBuilderList(t *testing.T) {
+	r := NewIncidentRoleBuilder(newIncidentTestClient())
+
+	resources, nextPageToken, _, err := r.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resources) != 3 || nextPageToken != "" {
+		t.Fatalf("Expected 3 incident roles on a single page, got %d and %q", len(resources), nextPageToken)
+	}
+
+	comms := resources[1]
+	if comms.DisplayName != "Communications Lead" || comms.Description != "Keeps stakeholders informed." {
+		t.Errorf("Unexpected incident role: %v", comms)
+	}
+
+	roleTrait, err := resource.GetRoleTrait(comms)
+	if err != nil {
+		t.Fatalf("Expected a role trait, got %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"incident_role_id": "01JQ5ROLECOMMS0000000000000",
+		"description":      "Keeps stakeholders informed.",
+		"instructions":     "Post status page updates every 30 minutes.",
+		"shortform":        "comms",
+		"role_type":        "custom",
+		"required":         false,
+		"created_at":       "2025-01-06T09:00:00Z",
+		"updated_at":       "2025-02-10T14:00:00Z",
+	}
+
+	if profile := roleTrait.GetProfile().AsMap(); !reflect.DeepEqual(profile, expected) {
+		t.Errorf("Unexpected profile: got %v, want %v", profile, expected)
+	}
+
+	leadTrait, err := resource.GetRoleTrait(resources[0])
+	if err != nil {
+		t.Fatalf("Expected a role trait, got %v", err)
+	}
+
+	if leadTrait.GetProfile().AsMap()["required"] != true {
+		t.Error("Expected the incident lead role to be required")
+	}
+}
@@ -0,0 +1,265 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// incidentBuilder syncs incidents, with one entitlement per incident role and a grant for every
+// user holding a role on an incident.
+type incidentBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.APIClient
+	filter       client.IncidentFilter
+
+	mu    sync.Mutex
+	roles []client.IncidentRole
+	// assignments holds the role assignments of the incidents listed during the sync, by incident ID,
+	// so that Grants does not need to fetch each incident again.
+	assignments map[string][]client.IncidentRoleAssignment
+}
+
+// ResourceType returns the resource type associated with incidents.
+func (o *incidentBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return incidentResourceType
+}
+
+// List retrieves a page of the incidents matching the configured filter. The first page drops the
+// incident roles and role assignments kept by the previous sync.
+func (o *incidentBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if pToken.Token == "" {
+		o.reset()
+	}
+
+	bag, pageToken, err := getToken(pToken, incidentResourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	incidents, nextPageToken, annos, err := o.client.ListIncidents(ctx, o.filter, client.PageOptions{
+		After:    pageToken,
+		PageSize: pToken.Size,
+	})
+	if err != nil {
+		l.Error("Error fetching incidents", zap.Error(err))
+		return nil, "", nil, fmt.Errorf("error fetching incidents: %w", err)
+	}
+
+	var resources []*v2.Resource
+	for _, incident := range incidents {
+		incidentResource, err := newIncidentResource(incident, incidentResourceType, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		o.keepAssignments(incident)
+		resources = append(resources, incidentResource)
+	}
+
+	err = bag.Next(nextPageToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPageToken, err = bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return resources, nextPageToken, annos, nil
+}
+
+// Entitlements returns an entitlement for each incident role, named after the role.
+func (o *incidentBuilder) Entitlements(ctx context.Context, incidentResource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	roles, err := o.incidentRoles(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	entitlements := make([]*v2.Entitlement, 0, len(roles))
+	for _, role := range roles {
+		entitlements = append(entitlements, entitlement.NewAssignmentEntitlement(
+			incidentResource,
+			role.ID,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s %s", incidentResource.DisplayName, role.Name)),
+			entitlement.WithDescription(fmt.Sprintf("%s of incident %s", role.Name, incidentResource.DisplayName)),
+		))
+	}
+
+	return entitlements, "", nil, nil
+}
+
+// Grants returns a grant for every incident role assigned to a user on the incident. The assignments
+// come from the incident list; an incident that was not listed by this builder is fetched instead.
+func (o *incidentBuilder) Grants(ctx context.Context, incidentResource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	incidentID := incidentResource.Id.Resource
+	if assignments, ok := o.takeAssignments(incidentID); ok {
+		grants, err := incidentRoleGrants(incidentResource, client.Incident{ID: incidentID, IncidentRoleAssignments: assignments})
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		return grants, "", nil, nil
+	}
+
+	incident, annos, err := o.client.GetIncident(ctx, incidentID)
+	if err != nil {
+		l.Error("Error fetching incident", zap.Error(err), zap.String("incident_id", incidentID))
+		return nil, "", nil, fmt.Errorf("error fetching incident %s: %w", incidentID, err)
+	}
+
+	grants, err := incidentRoleGrants(incidentResource, *incident)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return grants, "", annos, nil
+}
+
+// reset drops the incident roles and role assignments kept for a sync.
+func (o *incidentBuilder) reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.roles = nil
+	o.assignments = nil
+}
+
+// keepAssignments remembers the role assignments of a listed incident for Grants.
+func (o *incidentBuilder) keepAssignments(incident client.Incident) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.assignments == nil {
+		o.assignments = make(map[string][]client.IncidentRoleAssignment)
+	}
+
+	o.assignments[incident.ID] = incident.IncidentRoleAssignments
+}
+
+// takeAssignments returns the role assignments remembered for an incident and forgets them, as the
+// grants of each incident are read once per sync.
+func (o *incidentBuilder) takeAssignments(incidentID string) ([]client.IncidentRoleAssignment, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	assignments, ok := o.assignments[incidentID]
+	delete(o.assignments, incidentID)
+
+	return assignments, ok
+}
+
+// incidentRoles lists the incident roles once and caches them until List starts the next sync.
+func (o *incidentBuilder) incidentRoles(ctx context.Context) ([]client.IncidentRole, error) {
+	l := ctxzap.Extract(ctx)
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.roles != nil {
+		return o.roles, nil
+	}
+
+	roles, _, err := o.client.ListIncidentRoles(ctx)
+	if err != nil {
+		l.Error("Error fetching incident roles", zap.Error(err))
+		return nil, fmt.Errorf("error fetching incident roles: %w", err)
+	}
+
+	if roles == nil {
+		roles = []client.IncidentRole{}
+	}
+
+	o.roles = roles
+
+	return o.roles, nil
+}
+
+// incidentRoleGrants returns a grant of the role's entitlement for every assigned incident role.
+func incidentRoleGrants(incidentResource *v2.Resource, incident client.Incident) ([]*v2.Grant, error) {
+	var grants []*v2.Grant
+	for _, assignment := range incident.IncidentRoleAssignments {
+		if assignment.Assignee == nil || assignment.Assignee.ID == "" || assignment.Role.ID == "" {
+			continue
+		}
+
+		principalID, err := resource.NewResourceID(userResourceType, assignment.Assignee.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create resource ID for user: %s", assignment.Assignee.ID)
+		}
+
+		grants = append(grants, grant.NewGrant(incidentResource, assignment.Role.ID, principalID))
+	}
+
+	return grants, nil
+}
+
+// newIncidentResource creates a Baton resource of the given type from an incident, named after
+// its reference and name, e.g. "INC-123: Database unavailable".
+func newIncidentResource(incident client.Incident, resourceType *v2.ResourceType, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"incident_id":     incident.ID,
+		"reference":       incident.Reference,
+		"status":          incident.Status.Name,
+		"status_category": incident.Status.Category,
+		"visibility":      incident.Visibility,
+		"permalink":       incident.Permalink,
+	}
+
+	if incident.Severity != nil {
+		profile["severity"] = incident.Severity.Name
+	}
+
+	if incident.CreatedAt != nil {
+		profile["created_at"] = incident.CreatedAt.Format(time.RFC3339)
+	}
+
+	if incident.UpdatedAt != nil {
+		profile["updated_at"] = incident.UpdatedAt.Format(time.RFC3339)
+	}
+
+	displayName := incident.Name
+	if incident.Reference != "" {
+		displayName = fmt.Sprintf("%s: %s", incident.Reference, incident.Name)
+	}
+
+	incidentResource, err := resource.NewGroupResource(
+		displayName,
+		resourceType,
+		incident.ID,
+		[]resource.GroupTraitOption{resource.WithGroupProfile(profile)},
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithDescription(incident.Summary),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating incident resource: %w", err)
+	}
+
+	return incidentResource, nil
+}
+
+// NewIncidentBuilder initializes a new incident builder listing the incidents matching filter.
+func NewIncidentBuilder(c *client.APIClient, filter client.IncidentFilter) *incidentBuilder {
+	return &incidentBuilder{
+		resourceType: incidentResourceType,
+		client:       c,
+		filter:       filter,
+	}
+}
@@ -0,0 +1,204 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const (
+	dbOutageIncidentID    = "01JQ5INC0DBOUTAGE00000000"
+	payrollLeakIncidentID = "01JQ6INC0PAYROLLLEAK000000"
+)
+
+func newIncidentTestClient(opts ...test.RoutingOption) *client.APIClient {
+	return test.NewRoutingTestClient(map[string]string{
+		"/v2/incidents":                          "incidentsMock.json",
+		"/v2/incidents/" + dbOutageIncidentID:    "incidentMock.json",
+		"/v2/incidents/" + payrollLeakIncidentID: "privateIncidentMock.json",
+		"/v2/incident_roles":                     "incidentRolesMock.json",
+	}, opts...)
+}
+
+func TestIncidentBuilderList(t *testing.T) {
+	i := NewIncidentBuilder(newIncidentTestClient(), client.IncidentFilter{})
+
+	resources, _, _, err := i.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 incidents, got %d", len(resources))
+	}
+
+	if resources[0].DisplayName != "INC-42: Database unavailable" {
+		t.Errorf("Unexpected display name: %s", resources[0].DisplayName)
+	}
+
+	groupTrait, err := resource.GetGroupTrait(resources[0])
+	if err != nil {
+		t.Fatalf("Expected a group trait, got %v", err)
+	}
+
+	profile := groupTrait.GetProfile().AsMap()
+	expected := map[string]interface{}{
+		"incident_id":     dbOutageIncidentID,
+		"reference":       "INC-42",
+		"status":          "Fixing",
+		"status_category": "live",
+		"severity":        "Major",
+		"visibility":      "public",
+		"permalink":       "https://app.incident.io/example/incidents/42",
+		"created_at":      "2025-04-02T09:30:00Z",
+		"updated_at":      "2025-04-02T11:05:00Z",
+	}
+
+	if !reflect.DeepEqual(profile, expected) {
+		t.Errorf("Unexpected profile: got %v, want %v", profile, expected)
+	}
+}
+
+func TestIncidentBuilderList_Filter(t *testing.T) {
+	var query url.Values
+	c := test.NewRoutingTestClient(nil, test.WithHandler("GET /v2/incidents", func(req *http.Request, _ []byte) (int, string) {
+		query = req.URL.Query()
+		return http.StatusOK, `{"incidents":[],"pagination_meta":{"page_size":25}}`
+	}))
+	i := NewIncidentBuilder(c, client.IncidentFilter{
+		StatusCategories: []string{"live", "learning"},
+		CreatedAfter:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+
+	_, _, _, err := i.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !reflect.DeepEqual(query["status_category[one_of]"], []string{"live", "learning"}) {
+		t.Errorf("Unexpected status category filter: %v", query["status_category[one_of]"])
+	}
+
+	if query.Get("created_at[gte]") != "2025-01-01" {
+		t.Errorf("Unexpected created at filter: %q", query.Get("created_at[gte]"))
+	}
+}
+
+func TestIncidentBuilderEntitlements(t *testing.T) {
+	i := NewIncidentBuilder(newIncidentTestClient(), client.IncidentFilter{})
+
+	incidentResource := &v2.Resource{
+		Id:          &v2.ResourceId{ResourceType: incidentResourceType.Id, Resource: dbOutageIncidentID},
+		DisplayName: "INC-42: Database unavailable",
+	}
+
+	entitlements, _, _, err := i.Entitlements(context.Background(), incidentResource, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(entitlements) != 3 {
+		t.Fatalf("Expected one entitlement per incident role, got %d", len(entitlements))
+	}
+
+	if entitlements[0].Slug != "01JQ5ROLELEAD00000000000000" || entitlements[0].DisplayName != "INC-42: Database unavailable Incident Lead" {
+		t.Errorf("Unexpected entitlement: %v", entitlements[0])
+	}
+}
+
+func TestIncidentBuilderList_ResetsSync(t *testing.T) {
+	i := NewIncidentBuilder(newIncidentTestClient(), client.IncidentFilter{})
+
+	incidentResource := &v2.Resource{Id: &v2.ResourceId{ResourceType: incidentResourceType.Id, Resource: dbOutageIncidentID}}
+	if _, _, _, err := i.Entitlements(context.Background(), incidentResource, &pagination.Token{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if i.roles == nil {
+		t.Fatal("Expected the incident roles to be kept")
+	}
+
+	if _, _, _, err := i.List(context.Background(), nil, &pagination.Token{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if i.roles != nil {
+		t.Error("Expected the incident roles to be read again by the next sync")
+	}
+
+	if len(i.assignments) != 2 {
+		t.Errorf("Expected the assignments of the listed incidents to be kept, got %d", len(i.assignments))
+	}
+}
+
+func TestIncidentBuilderGrants(t *testing.T) {
+	testCases := []struct {
+		name string
+		// listed lists the incidents before reading grants, as a sync does.
+		listed           bool
+		expectedRequests []string
+	}{
+		{
+			name:             "listed incident",
+			listed:           true,
+			expectedRequests: []string{"/v2/incidents"},
+		},
+		{
+			name:             "incident not listed",
+			expectedRequests: []string{"/v2/incidents/" + dbOutageIncidentID},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []test.Request
+			i := NewIncidentBuilder(newIncidentTestClient(test.WithRecorder(&requests)), client.IncidentFilter{})
+
+			if tc.listed {
+				if _, _, _, err := i.List(context.Background(), nil, &pagination.Token{}); err != nil {
+					t.Fatalf("Expected no error listing, got %v", err)
+				}
+			}
+
+			incidentResource := &v2.Resource{Id: &v2.ResourceId{ResourceType: incidentResourceType.Id, Resource: dbOutageIncidentID}}
+
+			grants, _, _, err := i.Grants(context.Background(), incidentResource, &pagination.Token{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var actual []string
+			for _, g := range grants {
+				actual = append(actual, g.Principal.Id.Resource+":"+g.Entitlement.Id)
+			}
+
+			// The reporter role is not assigned, so it has no grant.
+			expected := []string{
+				"01JPWQNM50YGKQYFJYW61BBPD7:incident:" + dbOutageIncidentID + ":01JQ5ROLELEAD00000000000000",
+				"01JPWQP39ZE3X1NRHC3PJAWZVQ:incident:" + dbOutageIncidentID + ":01JQ5ROLECOMMS0000000000000",
+			}
+
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Unexpected grants: got %v, want %v", actual, expected)
+			}
+
+			var paths []string
+			for _, req := range requests {
+				paths = append(paths, req.Path)
+			}
+
+			if !reflect.DeepEqual(paths, tc.expectedRequests) {
+				t.Errorf("Unexpected requests: got %v, want %v", paths, tc.expectedRequests)
+			}
+		})
+	}
+}
@@ -32,7 +32,7 @@ func initClient(t *testing.T) *client.APIClient {
 func TestUserBuilderList(t *testing.T) {
 	c := initClient(t)
 
-	u := NewUserBuilder(c)
+	u := NewUserBuilder(c, newUserIndex(c))
 
 	res, _, _, err := u.List(ctx, parentResourceID, pToken)
 	assert.Nil(t, err)
@@ -44,7 +44,7 @@ func TestUserBuilderList(t *testing.T) {
 func TestScheduleBuilderList(t *testing.T) {
 	c := initClient(t)
 
-	s := NewScheduleBuilder(c)
+	s := NewScheduleBuilder(c, defaultOverrideDuration, defaultCoverageGapWindow)
 
 	res, _, _, err := s.List(ctx, parentResourceID, pToken)
 	assert.Nil(t, err)
@@ -12,8 +12,74 @@ var userResourceType = &v2.ResourceType{
 	Annotations: annotations.New(&v2.SkipEntitlementsAndGrants{}),
 }
 
+var roleResourceType = &v2.ResourceType{
+	Id:          "role",
+	DisplayName: "Role",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
+}
+
 var scheduleResourceType = &v2.ResourceType{
 	Id:          "schedule",
 	DisplayName: "schedule",
 	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
 }
+
+var rotationResourceType = &v2.ResourceType{
+	Id:          "rotation",
+	DisplayName: "Rotation",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+var escalationPathResourceType = &v2.ResourceType{
+	Id:          "escalation_path",
+	DisplayName: "Escalation Path",
+}
+
+var teamResourceType = &v2.ResourceType{
+	Id:          "team",
+	DisplayName: "Team",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+var catalogTypeResourceType = &v2.ResourceType{
+	Id:          "catalog_type",
+	DisplayName: "Catalog Type",
+	Annotations: annotations.New(&v2.SkipEntitlementsAndGrants{}),
+}
+
+var catalogEntryResourceType = &v2.ResourceType{
+	Id:          "catalog_entry",
+	DisplayName: "Catalog Entry",
+}
+
+var scimGroupResourceType = &v2.ResourceType{
+	Id:          "scim_group",
+	DisplayName: "SCIM Group",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+var incidentResourceType = &v2.ResourceType{
+	Id:          "incident",
+	DisplayName: "Incident",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
+
+var incidentRoleResourceType = &v2.ResourceType{
+	Id:          "incident_role",
+	DisplayName: "Incident Role",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_ROLE},
+	Annotations: annotations.New(&v2.SkipEntitlementsAndGrants{}),
+}
+
+var apiKeyResourceType = &v2.ResourceType{
+	Id:          "api_key",
+	DisplayName: "API Key",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_SECRET},
+	Annotations: annotations.New(&v2.SkipEntitlementsAndGrants{}),
+}
+
+var workflowResourceType = &v2.ResourceType{
+	Id:          "workflow",
+	DisplayName: "Workflow",
+	Traits:      []v2.ResourceType_Trait{v2.ResourceType_TRAIT_GROUP},
+}
@@ -0,0 +1,349 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const (
+	roleAssignedEntitlement = "assigned"
+
+	baseRoleType   = "base"
+	customRoleType = "custom"
+)
+
+// roleBuilder manages incident.io base roles and custom RBAC roles.
+type roleBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.APIClient
+	users        *userIndex
+
+	// fallbackBaseRoleID is the base role users are moved to when their base role is revoked.
+	fallbackBaseRoleID string
+}
+
+// ResourceType returns the resource type associated with roles.
+func (o *roleBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return roleResourceType
+}
+
+// List retrieves the roles held by users, one page of the user index at a time. The first page
+// refreshes the index for the sync.
+func (o *roleBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	if pToken.Token == "" {
+		o.users.refresh(roleResourceType.Id)
+	}
+
+	roles, err := o.users.Roles(ctx)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	start := 0
+	if pToken.Token != "" {
+		start, err = strconv.Atoi(pToken.Token)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("invalid role page token %q: %w", pToken.Token, err)
+		}
+	}
+
+	pageSize := pToken.Size
+	if pageSize == 0 {
+		pageSize = client.ItemsPerPage
+	}
+
+	start = min(start, len(roles))
+	end := min(start+pageSize, len(roles))
+
+	var resources []*v2.Resource
+	for _, role := range roles[start:end] {
+		roleResource, err := newRoleResource(role.Role, role.kind, parentResourceID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error creating role resource: %w", err)
+		}
+
+		resources = append(resources, roleResource)
+	}
+
+	nextPageToken := ""
+	if end < len(roles) {
+		nextPageToken = strconv.Itoa(end)
+	}
+
+	return resources, nextPageToken, nil, nil
+}
+
+// Entitlements returns the "assigned" entitlement for a role.
+func (o *roleBuilder) Entitlements(_ context.Context, roleResource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			roleResource,
+			roleAssignedEntitlement,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s role", roleResource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Assigned the %s role in incident.io", roleResource.DisplayName)),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns an "assigned" grant for every user holding the role as a base or custom role.
+func (o *roleBuilder) Grants(ctx context.Context, roleResource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	holders, err := o.users.Holders(ctx, roleResource.Id.Resource)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	var grants []*v2.Grant
+	for _, userID := range holders {
+		principalID, err := resource.NewResourceID(userResourceType, userID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create resource ID for user: %s", userID)
+		}
+
+		grants = append(grants, grant.NewGrant(roleResource, roleAssignedEntitlement, principalID))
+	}
+
+	return grants, "", nil, nil
+}
+
+// Grant assigns a role to a user. Granting a base role replaces the user's current base role,
+// granting a custom role adds it to the user's custom roles.
+func (o *roleBuilder) Grant(ctx context.Context, principal *v2.Resource, ent *v2.Entitlement) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"incident.io: only users can be granted roles",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, fmt.Errorf("incident.io: only users can be granted roles")
+	}
+
+	role, err := o.findRole(ctx, ent.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	user, _, err := o.client.GetUser(ctx, principal.Id.Resource)
+	if err != nil {
+		l.Error("Error fetching user", zap.Error(err), zap.String("user_id", principal.Id.Resource))
+		return nil, fmt.Errorf("error fetching user %s: %w", principal.Id.Resource, err)
+	}
+
+	baseRoleID := user.BaseRole.ID
+	customRoleIDs := customRoleIDs(*user)
+
+	switch role.kind {
+	case baseRoleType:
+		if baseRoleID == role.ID {
+			return annotations.New(&v2.GrantAlreadyExists{}), nil
+		}
+
+		baseRoleID = role.ID
+	default:
+		if slices.Contains(customRoleIDs, role.ID) {
+			return annotations.New(&v2.GrantAlreadyExists{}), nil
+		}
+
+		customRoleIDs = append(customRoleIDs, role.ID)
+	}
+
+	_, annos, err := o.client.UpdateUserRoles(ctx, user.ID, baseRoleID, customRoleIDs)
+	if err != nil {
+		l.Error("Error updating user roles", zap.Error(err), zap.String("user_id", user.ID))
+		return nil, fmt.Errorf("error granting role %s to user %s: %w", role.ID, user.ID, err)
+	}
+
+	return annos, nil
+}
+
+// Revoke removes a role from a user. A user always holds a base role and incident.io keeps no record
+// of the base role a user held before, so revoking a base role moves the user to the configured
+// fallback base role. Without one, base roles cannot be revoked.
+func (o *roleBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	principal := grant.Principal
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"incident.io: only users can have roles revoked",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, fmt.Errorf("incident.io: only users can have roles revoked")
+	}
+
+	role, err := o.findRole(ctx, grant.Entitlement.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	user, _, err := o.client.GetUser(ctx, principal.Id.Resource)
+	if err != nil {
+		l.Error("Error fetching user", zap.Error(err), zap.String("user_id", principal.Id.Resource))
+		return nil, fmt.Errorf("error fetching user %s: %w", principal.Id.Resource, err)
+	}
+
+	baseRoleID := user.BaseRole.ID
+	customRoleIDs := customRoleIDs(*user)
+
+	switch role.kind {
+	case baseRoleType:
+		if baseRoleID != role.ID {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+
+		if o.fallbackBaseRoleID == "" {
+			return nil, fmt.Errorf("incident.io: every user holds a base role, configure a fallback base role to revoke %s from user %s", role.ID, user.ID)
+		}
+
+		if o.fallbackBaseRoleID == role.ID {
+			return nil, fmt.Errorf("incident.io: %s is the fallback base role and cannot be revoked from user %s", role.ID, user.ID)
+		}
+
+		baseRoleID = o.fallbackBaseRoleID
+	default:
+		index := slices.Index(customRoleIDs, role.ID)
+		if index == -1 {
+			return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+		}
+
+		customRoleIDs = slices.Delete(customRoleIDs, index, index+1)
+	}
+
+	_, annos, err := o.client.UpdateUserRoles(ctx, user.ID, baseRoleID, customRoleIDs)
+	if err != nil {
+		l.Error("Error updating user roles", zap.Error(err), zap.String("user_id", user.ID))
+		return nil, fmt.Errorf("error revoking role %s from user %s: %w", role.ID, user.ID, err)
+	}
+
+	return annos, nil
+}
+
+// findRole returns the role of an entitlement. Role resources synced by the connector carry their
+// kind in their profile, so roles nobody holds any more still resolve without listing users. Other
+// roles are looked up in the user index.
+func (o *roleBuilder) findRole(ctx context.Context, roleResource *v2.Resource) (userRole, error) {
+	roleID := roleResource.Id.Resource
+
+	if roleTrait, err := resource.GetRoleTrait(roleResource); err == nil {
+		profile := roleTrait.GetProfile()
+		kind, _ := resource.GetProfileStringValue(profile, "role_type")
+		if kind == baseRoleType || kind == customRoleType {
+			slug, _ := resource.GetProfileStringValue(profile, "slug")
+			return userRole{
+				Role: client.Role{ID: roleID, Name: roleResource.DisplayName, Slug: slug},
+				kind: kind,
+			}, nil
+		}
+	}
+
+	roles, err := o.users.Roles(ctx)
+	if err != nil {
+		return userRole{}, err
+	}
+
+	for _, role := range roles {
+		if role.ID == roleID {
+			return role, nil
+		}
+	}
+
+	return userRole{}, fmt.Errorf("incident.io: role %s not found", roleID)
+}
+
+// findBaseRole returns the base role among roles whose ID is value or, failing that, whose slug or
+// name matches value.
+func findBaseRole(roles []userRole, value string) (client.Role, bool) {
+	for _, role := range roles {
+		if role.kind == baseRoleType && role.ID == value {
+			return role.Role, true
+		}
+	}
+
+	for _, role := range roles {
+		if role.kind == baseRoleType && (strings.EqualFold(role.Slug, value) || strings.EqualFold(role.Name, value)) {
+			return role.Role, true
+		}
+	}
+
+	return client.Role{}, false
+}
+
+// customRoleIDs returns the IDs of the custom roles assigned to a user.
+func customRoleIDs(user client.User) []string {
+	ids := make([]string, 0, len(user.CustomRoles))
+	for _, customRole := range user.CustomRoles {
+		ids = append(ids, customRole.ID)
+	}
+
+	return ids
+}
+
+// userRole is a role held by a user together with whether it is a base or custom role.
+type userRole struct {
+	client.Role
+	kind string
+}
+
+// userRoles returns the base role followed by the custom roles of a user.
+func userRoles(user client.User) []userRole {
+	var roles []userRole
+
+	if user.BaseRole.ID != "" {
+		roles = append(roles, userRole{Role: user.BaseRole, kind: baseRoleType})
+	}
+
+	for _, customRole := range user.CustomRoles {
+		if customRole.ID == "" {
+			continue
+		}
+
+		roles = append(roles, userRole{Role: customRole, kind: customRoleType})
+	}
+
+	return roles
+}
+
+// newRoleResource creates a Baton role resource from an incident.io role.
+func newRoleResource(role client.Role, kind string, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"role_id":     role.ID,
+		"slug":        role.Slug,
+		"description": role.Description,
+		"role_type":   kind,
+	}
+
+	return resource.NewRoleResource(
+		role.Name,
+		roleResourceType,
+		role.ID,
+		[]resource.RoleTraitOption{resource.WithRoleProfile(profile)},
+		resource.WithParentResourceID(parentResourceID),
+		resource.WithDescription(role.Description),
+	)
+}
+
+// NewRoleBuilder initializes a new role builder.
+func NewRoleBuilder(c *client.APIClient, users *userIndex, fallbackBaseRoleID string) *roleBuilder {
+	return &roleBuilder{
+		resourceType:       roleResourceType,
+		client:             c,
+		users:              users,
+		fallbackBaseRoleID: fallbackBaseRoleID,
+	}
+}
@@ -0,0 +1,359 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+func TestRoleBuilderList(t *testing.T) {
+	body, err := test.ReadFile("usersMock.json")
+	if err != nil {
+		t.Fatalf("Error reading body: %s", err)
+	}
+
+	r := newTestRoleBuilder(test.NewTestClient(test.NewMockResponse(http.StatusOK, body), nil))
+
+	resources, nextToken, _, err := r.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if nextToken != "" {
+		t.Errorf("Expected empty next page token, got %q", nextToken)
+	}
+
+	expected := map[string]string{
+		"01JPWQNJKADS4VZ8PEYV0PAQPA": "Owner",
+		"01JPWQNJKAC407555HM47MP2V4": "Standard",
+		"01JPWR2D8K4Q7TNB3V6XHCM0SA": "Workflow Editor",
+	}
+
+	if len(resources) != len(expected) {
+		t.Fatalf("Expected %d roles, got %d", len(expected), len(resources))
+	}
+
+	for _, res := range resources {
+		if res.Id.ResourceType != roleResourceType.Id {
+			t.Errorf("Expected resource type %s, got %s", roleResourceType.Id, res.Id.ResourceType)
+		}
+
+		if name, ok := expected[res.Id.Resource]; !ok || name != res.DisplayName {
+			t.Errorf("Unexpected role %s (%s)", res.Id.Resource, res.DisplayName)
+		}
+	}
+}
+
+// newTestRoleBuilder creates a role builder with its own user index and no fallback base role.
+func newTestRoleBuilder(c *client.APIClient) *roleBuilder {
+	return NewRoleBuilder(c, newUserIndex(c), "")
+}
+
+func TestRoleBuilderList_Pagination(t *testing.T) {
+	var requests []test.Request
+	r := newTestRoleBuilder(test.NewRoutingTestClient(map[string]string{"/v2/users": "usersMock.json"}, test.WithRecorder(&requests)))
+
+	var ids []string
+	pToken := &pagination.Token{Size: 2}
+	for {
+		resources, nextToken, _, err := r.List(context.Background(), nil, pToken)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(resources) > 2 {
+			t.Fatalf("Expected at most 2 roles per page, got %d", len(resources))
+		}
+
+		for _, res := range resources {
+			ids = append(ids, res.Id.Resource)
+		}
+
+		if nextToken == "" {
+			break
+		}
+
+		pToken = &pagination.Token{Size: 2, Token: nextToken}
+	}
+
+	expected := []string{"01JPWQNJKADS4VZ8PEYV0PAQPA", "01JPWQNJKAC407555HM47MP2V4", "01JPWR2D8K4Q7TNB3V6XHCM0SA"}
+	if !reflect.DeepEqual(ids, expected) {
+		t.Errorf("Unexpected roles: got %v, want %v", ids, expected)
+	}
+
+	// Grants of every role are served from the index built while listing them.
+	for _, id := range ids {
+		roleResource := &v2.Resource{Id: &v2.ResourceId{ResourceType: roleResourceType.Id, Resource: id}}
+		if _, _, _, err := r.Grants(context.Background(), roleResource, &pagination.Token{}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if len(requests) != 1 {
+		t.Errorf("Expected users to be listed once, got %d requests", len(requests))
+	}
+}
+
+func TestRoleBuilderGrants(t *testing.T) {
+	testCases := []struct {
+		name     string
+		roleID   string
+		expected []string
+	}{
+		{
+			name:     "base role",
+			roleID:   "01JPWQNJKADS4VZ8PEYV0PAQPA",
+			expected: []string{"01JPWQNM50YGKQYFJYW61BBPD7"},
+		},
+		{
+			name:     "custom role",
+			roleID:   "01JPWR2D8K4Q7TNB3V6XHCM0SA",
+			expected: []string{"01JPWQP39ZE3X1NRHC3PJAWZVQ"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			body, err := test.ReadFile("usersMock.json")
+			if err != nil {
+				t.Fatalf("Error reading body: %s", err)
+			}
+
+			r := newTestRoleBuilder(test.NewTestClient(test.NewMockResponse(http.StatusOK, body), nil))
+
+			roleResource := &v2.Resource{
+				Id: &v2.ResourceId{ResourceType: roleResourceType.Id, Resource: tc.roleID},
+			}
+
+			grants, _, _, err := r.Grants(context.Background(), roleResource, &pagination.Token{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(grants) != len(tc.expected) {
+				t.Fatalf("Expected %d grants, got %d", len(tc.expected), len(grants))
+			}
+
+			for i, g := range grants {
+				if g.Principal.Id.Resource != tc.expected[i] {
+					t.Errorf("Expected grant to %s, got %s", tc.expected[i], g.Principal.Id.Resource)
+				}
+
+				if g.Entitlement.Id != "role:"+tc.roleID+":"+roleAssignedEntitlement {
+					t.Errorf("Unexpected entitlement %s", g.Entitlement.Id)
+				}
+			}
+		})
+	}
+}
+
+const (
+	ownerRoleID          = "01JPWQNJKADS4VZ8PEYV0PAQPA"
+	standardRoleID       = "01JPWQNJKAC407555HM47MP2V4"
+	viewerRoleID         = "01JPWQNJKAVZ0V1EWER0000000"
+	workflowEditorRoleID = "01JPWR2D8K4Q7TNB3V6XHCM0SA"
+)
+
+// newRoleProvisioningTestClient serves the users mocks, answering role updates with the user as it was.
+func newRoleProvisioningTestClient(requests *[]test.Request) *client.APIClient {
+	return test.NewRoutingTestClient(map[string]string{
+		"GET /v2/users":                        "usersMock.json",
+		"/v2/users/01JPWQNM50YGKQYFJYW61BBPD7": "userOwnerMock.json",
+		"/v2/users/01JPWQP39ZE3X1NRHC3PJAWZVQ": "userStandardMock.json",
+	}, test.WithRecorder(requests))
+}
+
+// roleUpdates decodes the bodies of the role updates among requests.
+func roleUpdates(t *testing.T, requests []test.Request) []client.UpdateUserRolesRequest {
+	var updates []client.UpdateUserRolesRequest
+	for _, req := range requests {
+		if req.Method != http.MethodPut {
+			continue
+		}
+
+		var update client.UpdateUserRolesRequest
+		if err := json.Unmarshal(req.Body, &update); err != nil {
+			t.Fatalf("Error decoding update body: %s", err)
+		}
+		updates = append(updates, update)
+	}
+
+	return updates
+}
+
+func roleEntitlement(roleID string) *v2.Entitlement {
+	return &v2.Entitlement{
+		Id:       "role:" + roleID + ":" + roleAssignedEntitlement,
+		Resource: &v2.Resource{Id: &v2.ResourceId{ResourceType: roleResourceType.Id, Resource: roleID}},
+	}
+}
+
+// syncedRoleEntitlement returns the entitlement of a role resource as the connector syncs it.
+func syncedRoleEntitlement(t *testing.T, role client.Role, kind string) *v2.Entitlement {
+	roleResource, err := newRoleResource(role, kind, nil)
+	if err != nil {
+		t.Fatalf("Error creating role resource: %s", err)
+	}
+
+	return &v2.Entitlement{
+		Id:       "role:" + role.ID + ":" + roleAssignedEntitlement,
+		Resource: roleResource,
+	}
+}
+
+func userPrincipal(userID string) *v2.Resource {
+	return &v2.Resource{Id: &v2.ResourceId{ResourceType: userResourceType.Id, Resource: userID}}
+}
+
+func TestRoleBuilderGrant(t *testing.T) {
+	var requests []test.Request
+	r := newTestRoleBuilder(newRoleProvisioningTestClient(&requests))
+
+	// Alejandro already holds the custom role, so only the test user is updated.
+	annos, err := r.Grant(context.Background(), userPrincipal("01JPWQP39ZE3X1NRHC3PJAWZVQ"), roleEntitlement(workflowEditorRoleID))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !annos.Contains(&v2.GrantAlreadyExists{}) {
+		t.Error("Expected GrantAlreadyExists annotation")
+	}
+
+	_, err = r.Grant(context.Background(), userPrincipal("01JPWQNM50YGKQYFJYW61BBPD7"), roleEntitlement(workflowEditorRoleID))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []client.UpdateUserRolesRequest{
+		{
+			BaseRoleID:    ownerRoleID,
+			CustomRoleIDs: []string{workflowEditorRoleID},
+		},
+	}
+
+	if updates := roleUpdates(t, requests); !reflect.DeepEqual(updates, expected) {
+		t.Errorf("Unexpected updates: got %+v, want %+v", updates, expected)
+	}
+}
+
+func TestRoleBuilderGrant_UnheldRole(t *testing.T) {
+	var requests []test.Request
+	r := newTestRoleBuilder(newRoleProvisioningTestClient(&requests))
+
+	// Nobody holds the viewer role, but its synced resource says it is a base role.
+	viewer := client.Role{ID: viewerRoleID, Name: "Viewer", Slug: "viewer"}
+	_, err := r.Grant(context.Background(), userPrincipal("01JPWQP39ZE3X1NRHC3PJAWZVQ"), syncedRoleEntitlement(t, viewer, baseRoleType))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []client.UpdateUserRolesRequest{
+		{
+			BaseRoleID:    viewerRoleID,
+			CustomRoleIDs: []string{workflowEditorRoleID},
+		},
+	}
+
+	if updates := roleUpdates(t, requests); !reflect.DeepEqual(updates, expected) {
+		t.Errorf("Unexpected updates: got %+v, want %+v", updates, expected)
+	}
+
+	for _, req := range requests {
+		if req.Path == "/v2/users" {
+			t.Errorf("Expected the role to resolve without listing users, got %s %s", req.Method, req.Path)
+		}
+	}
+}
+
+func TestRoleBuilderRevoke(t *testing.T) {
+	testCases := []struct {
+		name     string
+		userID   string
+		roleID   string
+		expected client.UpdateUserRolesRequest
+	}{
+		{
+			name:   "base role moves the user to the fallback base role",
+			userID: "01JPWQNM50YGKQYFJYW61BBPD7",
+			roleID: ownerRoleID,
+			expected: client.UpdateUserRolesRequest{
+				BaseRoleID:    viewerRoleID,
+				CustomRoleIDs: []string{},
+			},
+		},
+		{
+			name:   "custom role is removed",
+			userID: "01JPWQP39ZE3X1NRHC3PJAWZVQ",
+			roleID: workflowEditorRoleID,
+			expected: client.UpdateUserRolesRequest{
+				BaseRoleID:    standardRoleID,
+				CustomRoleIDs: []string{},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []test.Request
+			c := newRoleProvisioningTestClient(&requests)
+			r := NewRoleBuilder(c, newUserIndex(c), viewerRoleID)
+
+			_, err := r.Revoke(context.Background(), &v2.Grant{
+				Entitlement: roleEntitlement(tc.roleID),
+				Principal:   userPrincipal(tc.userID),
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if updates := roleUpdates(t, requests); len(updates) != 1 || !reflect.DeepEqual(updates[0], tc.expected) {
+				t.Errorf("Unexpected updates: got %+v, want %+v", updates, tc.expected)
+			}
+		})
+	}
+}
+
+func TestRoleBuilderRevoke_BaseRoleWithoutFallback(t *testing.T) {
+	testCases := []struct {
+		name             string
+		fallbackBaseRole string
+		roleID           string
+	}{
+		{
+			name:   "no fallback base role configured",
+			roleID: standardRoleID,
+		},
+		{
+			name:             "revoking the fallback base role",
+			fallbackBaseRole: standardRoleID,
+			roleID:           standardRoleID,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []test.Request
+			c := newRoleProvisioningTestClient(&requests)
+			r := NewRoleBuilder(c, newUserIndex(c), tc.fallbackBaseRole)
+
+			_, err := r.Revoke(context.Background(), &v2.Grant{
+				Entitlement: roleEntitlement(tc.roleID),
+				Principal:   userPrincipal("01JPWQP39ZE3X1NRHC3PJAWZVQ"),
+			})
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			if updates := roleUpdates(t, requests); len(updates) != 0 {
+				t.Errorf("Expected no updates, got %+v", updates)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// rotationBuilder syncs the rotations of a schedule as child resources of the schedule.
+type rotationBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.APIClient
+}
+
+// ResourceType returns the resource type associated with rotations.
+func (o *rotationBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return rotationResourceType
+}
+
+// List retrieves the rotations of the parent schedule.
+func (o *rotationBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if parentResourceID == nil {
+		return nil, "", nil, nil
+	}
+
+	schedule, annos, err := o.client.GetSchedule(ctx, parentResourceID.Resource)
+	if err != nil {
+		l.Error("Error fetching schedule", zap.Error(err), zap.String("schedule_id", parentResourceID.Resource))
+		return nil, "", nil, fmt.Errorf("error fetching schedule %s: %w", parentResourceID.Resource, err)
+	}
+
+	var resources []*v2.Resource
+	now := time.Now()
+	for _, rotation := range effectiveRotations(schedule.Config.Rotation, now) {
+		rotationResource, err := resource.NewGroupResource(
+			rotation.Name,
+			rotationResourceType,
+			rotation.ID,
+			[]resource.GroupTraitOption{resource.WithGroupProfile(rotationProfile(schedule.ID, rotation, now))},
+			resource.WithParentResourceID(parentResourceID),
+		)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error creating rotation resource: %w", err)
+		}
+
+		resources = append(resources, rotationResource)
+	}
+
+	return resources, "", annos, nil
+}
+
+// Entitlements returns the on call and member entitlements of a rotation.
+func (o *rotationBuilder) Entitlements(_ context.Context, rotationResource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewPermissionEntitlement(
+			rotationResource,
+			scheduleOnCallEntitlement,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Rotation On Call", rotationResource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Currently on call for the %s rotation", rotationResource.DisplayName)),
+		),
+		entitlement.NewPermissionEntitlement(
+			rotationResource,
+			scheduleMemberEntitlement,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Rotation Member", rotationResource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Takes shifts in the %s rotation", rotationResource.DisplayName)),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns a Member grant for every user of the current and upcoming versions of the rotation
+// and an On_Call grant for the users holding one of its current shifts. Unlike schedules, on call
+// users keep their Member grant.
+func (o *rotationBuilder) Grants(ctx context.Context, rotationResource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if rotationResource.ParentResourceId == nil {
+		return nil, "", nil, fmt.Errorf("rotation %s has no parent schedule", rotationResource.Id.Resource)
+	}
+
+	scheduleID := rotationResource.ParentResourceId.Resource
+	schedule, annos, err := o.client.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		l.Error("Error fetching schedule", zap.Error(err), zap.String("schedule_id", scheduleID))
+		return nil, "", nil, fmt.Errorf("error fetching schedule %s: %w", scheduleID, err)
+	}
+
+	var grants []*v2.Grant
+
+	onCallUsers := make(map[string]bool)
+	for _, shift := range schedule.CurrentShifts {
+		if shift.RotationID != rotationResource.Id.Resource || !isAssignedShiftUser(shift.User) || onCallUsers[shift.User.ID] {
+			continue
+		}
+
+		onCallUsers[shift.User.ID] = true
+
+		principalID, err := resource.NewResourceID(userResourceType, shift.User.ID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create resource ID for user: %s", shift.User.ID)
+		}
+
+		grants = append(grants, grant.NewGrant(rotationResource, scheduleOnCallEntitlement, principalID))
+	}
+
+	memberUsers := make(map[string]bool)
+	activeVersions := activeRotationVersions(schedule.Config.Rotation, time.Now())
+	for index, rotation := range schedule.Config.Rotation {
+		if rotation.ID != rotationResource.Id.Resource || !activeVersions[index] {
+			continue
+		}
+
+		for _, user := range rotation.Users {
+			if !isAssignedShiftUser(user) || memberUsers[user.ID] {
+				continue
+			}
+
+			memberUsers[user.ID] = true
+
+			principalID, err := resource.NewResourceID(userResourceType, user.ID)
+			if err != nil {
+				return nil, "", nil, fmt.Errorf("failed to create resource ID for user: %s", user.ID)
+			}
+
+			grants = append(grants, grant.NewGrant(rotationResource, scheduleMemberEntitlement, principalID))
+		}
+	}
+
+	return grants, "", annos, nil
+}
+
+// Grant adds a user to the current and upcoming versions of a rotation through its Member entitlement.
+func (o *rotationBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"incident.io: only users can be added to rotations",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, fmt.Errorf("incident.io: only users can be added to rotations")
+	}
+
+	if !strings.HasSuffix(entitlement.Id, ":"+scheduleMemberEntitlement) {
+		return nil, fmt.Errorf("incident.io: only the %s entitlement of a rotation can be granted, on call users follow the rotation", scheduleMemberEntitlement)
+	}
+
+	rotationResource := entitlement.Resource
+	if rotationResource.ParentResourceId == nil {
+		return nil, fmt.Errorf("rotation %s has no parent schedule", rotationResource.Id.Resource)
+	}
+
+	changed, annos, err := updateRotationMembers(ctx, o.client, rotationResource.ParentResourceId.Resource, rotationResource.Id.Resource, principal.Id.Resource, true)
+	if err != nil {
+		return nil, fmt.Errorf("error adding user %s to rotation %s: %w", principal.Id.Resource, rotationResource.Id.Resource, err)
+	}
+
+	if !changed {
+		return annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	return annos, nil
+}
+
+// Revoke removes a user from the current and upcoming versions of a rotation. Both Member and
+// On_Call grants can be revoked.
+func (o *rotationBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	principal := grant.Principal
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"incident.io: only users can be removed from rotations",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, fmt.Errorf("incident.io: only users can be removed from rotations")
+	}
+
+	rotationResource := grant.Entitlement.Resource
+	if rotationResource.ParentResourceId == nil {
+		return nil, fmt.Errorf("rotation %s has no parent schedule", rotationResource.Id.Resource)
+	}
+
+	changed, annos, err := updateRotationMembers(ctx, o.client, rotationResource.ParentResourceId.Resource, rotationResource.Id.Resource, principal.Id.Resource, false)
+	if err != nil {
+		return nil, fmt.Errorf("error removing user %s from rotation %s: %w", principal.Id.Resource, rotationResource.Id.Resource, err)
+	}
+
+	if !changed {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	return annos, nil
+}
+
+// effectiveRotations returns a single version of each rotation, in the order the rotations are
+// first listed: the latest version in effect at now or, for a rotation that only takes effect in
+// the future, its earliest version.
+func effectiveRotations(rotations []client.Rotation, now time.Time) []client.Rotation {
+	effective := make([]client.Rotation, 0, len(rotations))
+	for _, index := range currentRotationVersions(rotations, now) {
+		effective = append(effective, rotations[index])
+	}
+
+	return effective
+}
+
+// activeRotationVersions reports, for each version in rotations, whether it is the version of its
+// rotation shown at now or takes effect after now. Versions superseded before now are inactive.
+func activeRotationVersions(rotations []client.Rotation, now time.Time) []bool {
+	active := make([]bool, len(rotations))
+	for index, rotation := range rotations {
+		active[index] = !rotationInEffect(rotation, now)
+	}
+
+	for _, index := range currentRotationVersions(rotations, now) {
+		active[index] = true
+	}
+
+	return active
+}
+
+// currentRotationVersions returns the index of the version of each rotation shown at now,
+// in the order the rotations are first listed.
+func currentRotationVersions(rotations []client.Rotation, now time.Time) []int {
+	var ids []string
+	selected := make(map[string]int)
+	for index, rotation := range rotations {
+		current, ok := selected[rotation.ID]
+		if !ok {
+			ids = append(ids, rotation.ID)
+			selected[rotation.ID] = index
+			continue
+		}
+
+		if laterVersionInEffect(rotation, rotations[current], now) {
+			selected[rotation.ID] = index
+		}
+	}
+
+	indexes := make([]int, 0, len(ids))
+	for _, id := range ids {
+		indexes = append(indexes, selected[id])
+	}
+
+	return indexes
+}
+
+// laterVersionInEffect reports whether candidate should replace current as the version of a rotation shown at now.
+func laterVersionInEffect(candidate, current client.Rotation, now time.Time) bool {
+	candidateInEffect := rotationInEffect(candidate, now)
+	currentInEffect := rotationInEffect(current, now)
+
+	switch {
+	case candidateInEffect != currentInEffect:
+		return candidateInEffect
+	case current.EffectiveFrom == nil || candidate.EffectiveFrom == nil:
+		return false
+	case candidateInEffect:
+		return candidate.EffectiveFrom.After(*current.EffectiveFrom)
+	default:
+		return candidate.EffectiveFrom.Before(*current.EffectiveFrom)
+	}
+}
+
+// rotationInEffect reports whether a rotation version has taken effect at now. Versions without
+// an effective_from are always in effect.
+func rotationInEffect(rotation client.Rotation, now time.Time) bool {
+	return rotation.EffectiveFrom == nil || !rotation.EffectiveFrom.After(now)
+}
+
+// rotationProfile describes the cadence of a rotation version and whether it is in effect at now.
+func rotationProfile(scheduleID string, rotation client.Rotation, now time.Time) map[string]interface{} {
+	profile := map[string]interface{}{
+		"rotation_id": rotation.ID,
+		"schedule_id": scheduleID,
+		"in_effect":   rotationInEffect(rotation, now),
+		"layer_count": len(rotation.Layers),
+	}
+
+	if rotation.EffectiveFrom != nil {
+		profile["effective_from"] = rotation.EffectiveFrom.Format(time.RFC3339)
+	}
+
+	if rotation.HandoverStartAt != nil {
+		profile["handover_start_at"] = rotation.HandoverStartAt.Format(time.RFC3339)
+	}
+
+	var layers []string
+	for _, layer := range rotation.Layers {
+		layers = append(layers, layer.Name)
+	}
+	if len(layers) > 0 {
+		profile["layers"] = strings.Join(layers, ", ")
+	}
+
+	var handovers []string
+	for _, handover := range rotation.Handovers {
+		handovers = append(handovers, fmt.Sprintf("%d %s", handover.Interval, handover.IntervalType))
+	}
+	if len(handovers) > 0 {
+		profile["handovers"] = strings.Join(handovers, ", ")
+	}
+
+	var workingIntervals []string
+	for _, interval := range rotation.WorkingInterval {
+		workingIntervals = append(workingIntervals, fmt.Sprintf("%s %s-%s", interval.Weekday, interval.StartTime, interval.EndTime))
+	}
+	if len(workingIntervals) > 0 {
+		profile["working_intervals"] = strings.Join(workingIntervals, ", ")
+	}
+
+	return profile
+}
+
+// NewRotationBuilder initializes a new rotation builder.
+func NewRotationBuilder(c *client.APIClient) *rotationBuilder {
+	return &rotationBuilder{
+		resourceType: rotationResourceType,
+		client:       c,
+	}
+}
@@ -0,0 +1,316 @@
+package connector
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const (
+	weeklyRotationID  = "01JQ77YN7BRVRA81T9STQ41ROT"
+	weekendRotationID = "01JQ77YN7BRVRA81T9STQ4WKND"
+)
+
+func rotationResourceFor(scheduleID, rotationID string) *v2.Resource {
+	return &v2.Resource{
+		Id:               &v2.ResourceId{ResourceType: rotationResourceType.Id, Resource: rotationID},
+		ParentResourceId: &v2.ResourceId{ResourceType: scheduleResourceType.Id, Resource: scheduleID},
+	}
+}
+
+func TestRotationBuilderList(t *testing.T) {
+	var requested []string
+	b := NewRotationBuilder(newScheduleTestClient(t, &requested))
+
+	parentID := &v2.ResourceId{ResourceType: scheduleResourceType.Id, Resource: primaryScheduleID}
+	resources, _, _, err := b.List(context.Background(), parentID, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]string{
+		weeklyRotationID:  "Weekly",
+		weekendRotationID: "Weekend",
+	}
+	if len(resources) != len(expected) {
+		t.Fatalf("Expected %d rotations, got %d", len(expected), len(resources))
+	}
+
+	for _, res := range resources {
+		if res.DisplayName != expected[res.Id.Resource] {
+			t.Errorf("Unexpected rotation %s (%s)", res.DisplayName, res.Id.Resource)
+		}
+
+		if res.ParentResourceId.GetResource() != primaryScheduleID {
+			t.Errorf("Expected rotation %s to be parented by schedule %s, got %v", res.Id.Resource, primaryScheduleID, res.ParentResourceId)
+		}
+	}
+}
+
+func TestRotationBuilderList_NoParent(t *testing.T) {
+	var requested []string
+	b := NewRotationBuilder(newScheduleTestClient(t, &requested))
+
+	resources, _, _, err := b.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resources) != 0 || len(requested) != 0 {
+		t.Errorf("Expected no rotations and no requests, got %d rotations and requests %v", len(resources), requested)
+	}
+}
+
+func TestRotationBuilderGrants(t *testing.T) {
+	testCases := []struct {
+		name       string
+		rotationID string
+		expected   []string
+	}{
+		{
+			name:       "rotation with an on call user",
+			rotationID: weeklyRotationID,
+			expected: []string{
+				"01JPWQNM50YGKQYFJYW61BBPD7:On_Call",
+				"01JPWQNM50YGKQYFJYW61BBPD7:Member",
+				"01JPWQP39ZE3X1NRHC3PJAWZVQ:Member",
+			},
+		},
+		{
+			name:       "rotation without current shifts",
+			rotationID: weekendRotationID,
+			expected: []string{
+				"01JPWQP39ZE3X1NRHC3PJAWZVQ:Member",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requested []string
+			b := NewRotationBuilder(newScheduleTestClient(t, &requested))
+
+			grants, _, _, err := b.Grants(context.Background(), rotationResourceFor(primaryScheduleID, tc.rotationID), &pagination.Token{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var got []string
+			for _, g := range grants {
+				if g.Entitlement.Resource.Id.Resource != tc.rotationID {
+					t.Errorf("Grant for rotation %s emitted while syncing %s", g.Entitlement.Resource.Id.Resource, tc.rotationID)
+				}
+
+				parts := strings.Split(g.Entitlement.Id, ":")
+				got = append(got, g.Principal.Id.Resource+":"+parts[len(parts)-1])
+			}
+
+			if strings.Join(got, ",") != strings.Join(tc.expected, ",") {
+				t.Errorf("Expected grants %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestScheduleBuilderList_RotationChildType(t *testing.T) {
+	var requested []string
+	s := NewScheduleBuilder(newScheduleTestClient(t, &requested), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	resources, _, _, err := s.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 schedules, got %d", len(resources))
+	}
+
+	for _, res := range resources {
+		annos := annotations.Annotations(res.Annotations)
+		childType := &v2.ChildResourceType{}
+		ok, err := annos.Pick(childType)
+		if err != nil || !ok || childType.ResourceTypeId != rotationResourceType.Id {
+			t.Errorf("Expected schedule %s to declare rotation children", res.Id.Resource)
+		}
+	}
+}
+
+func TestRotationBuilderList_Profile(t *testing.T) {
+	var requested []string
+	b := NewRotationBuilder(newScheduleTestClient(t, &requested))
+
+	parentID := &v2.ResourceId{ResourceType: scheduleResourceType.Id, Resource: primaryScheduleID}
+	resources, _, _, err := b.List(context.Background(), parentID, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]map[string]interface{}{
+		weeklyRotationID: {
+			"in_effect":         true,
+			"effective_from":    "2025-01-06T09:00:00Z",
+			"handover_start_at": "2025-01-06T09:00:00Z",
+			"handovers":         "1 weekly",
+			"layers":            "Primary",
+			"working_intervals": "monday 09:00-17:00, tuesday 09:00-17:00",
+		},
+		weekendRotationID: {
+			"in_effect":      false,
+			"effective_from": "2099-01-03T00:00:00Z",
+			"handovers":      "1 daily",
+		},
+	}
+
+	for _, res := range resources {
+		groupTrait, err := resource.GetGroupTrait(res)
+		if err != nil {
+			t.Fatalf("Expected a group trait on rotation %s, got %v", res.Id.Resource, err)
+		}
+
+		profile := groupTrait.GetProfile().AsMap()
+		for key, value := range expected[res.Id.Resource] {
+			if profile[key] != value {
+				t.Errorf("Expected %s=%v on rotation %s, got %v", key, value, res.Id.Resource, profile[key])
+			}
+		}
+	}
+}
+
+func TestEffectiveRotations(t *testing.T) {
+	at := func(value string) *time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatalf("Error parsing time: %s", err)
+		}
+
+		return &parsed
+	}
+
+	now := *at("2025-06-01T00:00:00Z")
+	rotations := []client.Rotation{
+		{ID: "a", Name: "old", EffectiveFrom: at("2025-01-01T00:00:00Z")},
+		{ID: "b", Name: "later", EffectiveFrom: at("2026-02-01T00:00:00Z")},
+		{ID: "a", Name: "current", EffectiveFrom: at("2025-05-01T00:00:00Z")},
+		{ID: "a", Name: "future", EffectiveFrom: at("2025-07-01T00:00:00Z")},
+		{ID: "b", Name: "next", EffectiveFrom: at("2026-01-01T00:00:00Z")},
+	}
+
+	effective := effectiveRotations(rotations, now)
+	if len(effective) != 2 {
+		t.Fatalf("Expected 2 rotations, got %d", len(effective))
+	}
+
+	if effective[0].Name != "current" {
+		t.Errorf("Expected the latest version in effect for rotation a, got %s", effective[0].Name)
+	}
+
+	if effective[1].Name != "next" {
+		t.Errorf("Expected the earliest upcoming version for rotation b, got %s", effective[1].Name)
+	}
+}
+
+func TestScheduleBuilderList_Profile(t *testing.T) {
+	var requested []string
+	s := NewScheduleBuilder(newScheduleTestClient(t, &requested), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	resources, _, _, err := s.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, res := range resources {
+		if res.Id.Resource != primaryScheduleID {
+			continue
+		}
+
+		groupTrait, err := resource.GetGroupTrait(res)
+		if err != nil {
+			t.Fatalf("Expected a group trait on schedule %s, got %v", res.Id.Resource, err)
+		}
+
+		profile := groupTrait.GetProfile().AsMap()
+		if profile["timezone"] != "Europe/London" || profile["rotation_count"] != float64(2) || profile["rotations_in_effect"] != "Weekly" {
+			t.Errorf("Unexpected schedule profile %v", profile)
+		}
+	}
+}
+
+func TestRotationBuilderGrant(t *testing.T) {
+	const newUserID = "01JPWQZZ4NEWUSER0000000000"
+
+	var requests []test.Request
+	b := NewRotationBuilder(newScheduleProvisioningTestClient(t, nil, &requests))
+
+	rotationEntitlement := &v2.Entitlement{
+		Id:       strings.Join([]string{rotationResourceType.Id, weekendRotationID, scheduleMemberEntitlement}, ":"),
+		Resource: rotationResourceFor(primaryScheduleID, weekendRotationID),
+	}
+
+	_, err := b.Grant(context.Background(), userPrincipal(newUserID), rotationEntitlement)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	updates := scheduleUpdates(t, requests)
+	if len(updates) != 1 {
+		t.Fatalf("Expected 1 schedule update, got %d", len(updates))
+	}
+
+	for _, users := range rotationUsers(updates[0], weekendRotationID) {
+		if !slices.Contains(users, newUserID) {
+			t.Errorf("Expected the Weekend rotation to include the user, got %v", users)
+		}
+	}
+
+	for _, users := range rotationUsers(updates[0], weeklyRotationID) {
+		if slices.Contains(users, newUserID) {
+			t.Errorf("Expected the Weekly rotation to be left unchanged, got %v", users)
+		}
+	}
+}
+
+func TestRotationBuilderRevoke(t *testing.T) {
+	const userID = "01JPWQP39ZE3X1NRHC3PJAWZVQ"
+
+	var requests []test.Request
+	b := NewRotationBuilder(newScheduleProvisioningTestClient(t, nil, &requests))
+
+	grant := &v2.Grant{
+		Entitlement: &v2.Entitlement{
+			Id:       strings.Join([]string{rotationResourceType.Id, weekendRotationID, scheduleMemberEntitlement}, ":"),
+			Resource: rotationResourceFor(primaryScheduleID, weekendRotationID),
+		},
+		Principal: userPrincipal(userID),
+	}
+
+	_, err := b.Revoke(context.Background(), grant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	updates := scheduleUpdates(t, requests)
+	if len(updates) != 1 {
+		t.Fatalf("Expected 1 schedule update, got %d", len(updates))
+	}
+
+	for _, users := range rotationUsers(updates[0], weekendRotationID) {
+		if slices.Contains(users, userID) {
+			t.Errorf("Expected the user to be removed from the Weekend rotation, got %v", users)
+		}
+	}
+
+	for _, users := range rotationUsers(updates[0], weeklyRotationID) {
+		if !slices.Contains(users, userID) {
+			t.Errorf("Expected the user to stay in the Weekly rotation, got %v", users)
+		}
+	}
+}
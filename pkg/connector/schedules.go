@@ -3,6 +3,10 @@ package connector
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/conductorone/baton-incident-io/pkg/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
@@ -15,11 +19,27 @@ import (
 	"go.uber.org/zap"
 )
 
+const (
+	scheduleOnCallEntitlement = "On_Call"
+	scheduleMemberEntitlement = "Member"
+
+	// scheduleOverrideEntitlement is held by users covering shifts of the schedule through an override.
+	scheduleOverrideEntitlement = "Override"
+
+	// overrideLookahead is how far ahead upcoming overrides are read.
+	overrideLookahead = 365 * 24 * time.Hour
+)
+
+// overrideLister lists a page of the overrides of a schedule, either from the HTTP cache or not.
+type overrideLister func(ctx context.Context, scheduleID string, from, to time.Time, options client.PageOptions) ([]client.ScheduleOverride, string, annotations.Annotations, error)
+
 // scheduleBuilder handles resource type and client interactions
 // for managing schedule resources.
 type scheduleBuilder struct {
-	resourceType *v2.ResourceType
-	client       *client.APIClient
+	resourceType      *v2.ResourceType
+	client            *client.APIClient
+	overrideDuration  time.Duration
+	coverageGapWindow time.Duration
 }
 
 // ResourceType returns the resource type associated with schedules.
@@ -37,7 +57,7 @@ func (o *scheduleBuilder) List(ctx context.Context, parentResourceID *v2.Resourc
 	}
 
 	// Fetch schedules from the API
-	resp, nextPageToken, _, err := o.client.ListSchedules(ctx, client.PageOptions{
+	resp, nextPageToken, annos, err := o.client.ListSchedules(ctx, client.PageOptions{
 		After:    pageToken,
 		PageSize: pToken.Size,
 	})
@@ -48,15 +68,28 @@ func (o *scheduleBuilder) List(ctx context.Context, parentResourceID *v2.Resourc
 
 	var resources []*v2.Resource
 
+	now := time.Now()
 	for _, schedule := range resp {
 		scheduleCopy := schedule
 
+		profile := scheduleProfile(scheduleCopy, now)
+		if o.coverageGapWindow > 0 {
+			// Coverage gaps are informational, so a schedule whose entries cannot be read is still synced without them.
+			gaps, err := o.upcomingGaps(ctx, scheduleCopy.ID, now)
+			if err != nil {
+				l.Warn("Error fetching schedule entries, leaving coverage gaps off the profile", zap.Error(err), zap.String("schedule_id", scheduleCopy.ID))
+			} else {
+				addGapProfile(profile, gaps, o.coverageGapWindow)
+			}
+		}
+
 		scheduleResource, err := resource.NewGroupResource(
 			scheduleCopy.Name,
 			scheduleResourceType,
 			scheduleCopy.ID,
-			nil,
+			[]resource.GroupTraitOption{resource.WithGroupProfile(profile)},
 			resource.WithParentResourceID(parentResourceID),
+			resource.WithAnnotation(&v2.ChildResourceType{ResourceTypeId: rotationResourceType.Id}),
 		)
 		if err != nil {
 			return nil, "", nil, fmt.Errorf("error creating schedule resource: %w", err)
@@ -75,14 +108,15 @@ func (o *scheduleBuilder) List(ctx context.Context, parentResourceID *v2.Resourc
 		return nil, "", nil, err
 	}
 
-	return resources, nextPageToken, nil, nil
+	return resources, nextPageToken, annos, nil
 }
 
 // Entitlements returns predefined roles associated with schedules.
 func (o *scheduleBuilder) Entitlements(ctx context.Context, teamResource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
 	entitlementRoles := []string{
-		"On_Call",
-		"Member",
+		scheduleOnCallEntitlement,
+		scheduleMemberEntitlement,
+		scheduleOverrideEntitlement,
 	}
 
 	var entitlements []*v2.Entitlement
@@ -98,105 +132,546 @@ func (o *scheduleBuilder) Entitlements(ctx context.Context, teamResource *v2.Res
 	return entitlements, "", nil, nil
 }
 
-// Grants assigns {'on call','member'} to users based on their schedule participation.
-func (o *scheduleBuilder) Grants(ctx context.Context, scheduleResource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+// Grants assigns {'on call','member'} to users based on their participation in the given schedule.
+func (o *scheduleBuilder) Grants(ctx context.Context, scheduleResource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
 	l := ctxzap.Extract(ctx)
 
-	bag, pageToken, err := getToken(pToken, userResourceType)
-	if err != nil {
-		return nil, "", nil, err
-	}
-
-	// Fetch schedules from the API
-	schedulesResp, nextPageToken, _, err := o.client.ListSchedules(ctx, client.PageOptions{
-		After:    pageToken,
-		PageSize: pToken.Size,
-	})
+	schedule, annos, err := o.client.GetSchedule(ctx, scheduleResource.Id.Resource)
 	if err != nil {
-		l.Error("Error fetching schedules", zap.Error(err))
-		return nil, "", nil, fmt.Errorf("error fetching schedules: %w", err)
+		l.Error("Error fetching schedule", zap.Error(err), zap.String("schedule_id", scheduleResource.Id.Resource))
+		return nil, "", nil, fmt.Errorf("error fetching schedule %s: %w", scheduleResource.Id.Resource, err)
 	}
 
 	var grants []*v2.Grant
 
-	for _, schedule := range schedulesResp {
-		scheduleRes := &v2.Resource{
-			Id: &v2.ResourceId{
-				ResourceType: "schedule",
-				Resource:     schedule.ID,
-			},
-			DisplayName: schedule.Name,
+	onCallUsers := make(map[string]bool)
+
+	// users "On Call"
+	for _, shift := range schedule.CurrentShifts {
+		if !isAssignedShiftUser(shift.User) {
+			continue
 		}
 
-		onCallUsers := make(map[string]bool)
+		if onCallUsers[shift.User.ID] {
+			continue
+		}
 
-		// users "On Call"
-		for _, shift := range schedule.CurrentShifts {
-			if shift.User.ID == "" || shift.User.Email == "" || shift.User.ID == "NOBODY" {
-				continue
-			}
+		onCallUsers[shift.User.ID] = true
 
-			onCallUsers[shift.User.ID] = true
+		grant, err := createGrant(scheduleResource, client.User{
+			ID:    shift.User.ID,
+			Email: shift.User.Email,
+		}, scheduleOnCallEntitlement)
+		if err != nil {
+			l.Error("Error creating grant", zap.Error(err))
+			continue
+		}
 
-			grant, err := createGrant(scheduleRes, client.User{
-				ID:    shift.User.ID,
-				Email: shift.User.Email,
-			}, "On_Call")
-			if err != nil {
-				l.Error("Error creating grant", zap.Error(err))
+		if grant != nil {
+			grants = append(grants, grant)
+		}
+	}
+
+	// users "Member"
+	seenUsers := make(map[string]bool) // Duplicateds
+	activeVersions := activeRotationVersions(schedule.Config.Rotation, time.Now())
+	for index, rotation := range schedule.Config.Rotation {
+		if !activeVersions[index] {
+			continue
+		}
+
+		for _, user := range rotation.Users {
+			if !isAssignedShiftUser(user) {
 				continue
 			}
 
-			if grant != nil {
-				grants = append(grants, grant)
-			}
-		}
+			// Some users could be "On Call"
+			if _, exists := onCallUsers[user.ID]; !exists {
+				if seenUsers[user.ID] {
+					l.Warn("Duplicate user detected", zap.String("user_id", user.ID))
+					continue
+				}
 
-		// users "Member"
-		seenUsers := make(map[string]bool) // Duplicateds
-		for _, rotation := range schedule.Config.Rotation {
-			for _, user := range rotation.Users {
-				if user.ID == "NOBODY" || user.ID == "" || user.Email == "" {
+				seenUsers[user.ID] = true
+
+				grant, err := createGrant(scheduleResource, client.User{
+					ID:    user.ID,
+					Email: user.Email,
+				}, scheduleMemberEntitlement)
+				if err != nil {
+					l.Error("Error creating grant", zap.Error(err))
 					continue
 				}
 
-				// Some users could be "On Call"
-				if _, exists := onCallUsers[user.ID]; !exists {
-					if seenUsers[user.ID] {
-						l.Warn("Duplicate user detected", zap.String("user_id", user.ID))
-						continue
-					}
-
-					seenUsers[user.ID] = true
-
-					grant, err := createGrant(scheduleRes, client.User{
-						ID:    user.ID,
-						Email: user.Email,
-					}, "Member")
-					if err != nil {
-						l.Error("Error creating grant", zap.Error(err))
-						continue
-					}
-
-					if grant != nil {
-						grants = append(grants, grant)
-					}
+				if grant != nil {
+					grants = append(grants, grant)
 				}
 			}
 		}
 	}
 
-	err = bag.Next(nextPageToken)
+	overrideGrants, err := o.overrideGrants(ctx, scheduleResource, time.Now())
 	if err != nil {
 		return nil, "", nil, err
 	}
 
-	nextPageToken, err = bag.Marshal()
+	grants = append(grants, overrideGrants...)
+
+	return grants, "", annos, nil
+}
+
+// overrideGrants returns an Override grant for every user covering a current or upcoming override of the
+// schedule. The start and end of the user's overrides are carried in the grant metadata.
+func (o *scheduleBuilder) overrideGrants(ctx context.Context, scheduleResource *v2.Resource, now time.Time) ([]*v2.Grant, error) {
+	overrides, err := activeOverrides(ctx, o.client.ListScheduleOverrides, scheduleResource.Id.Resource, now)
 	if err != nil {
-		return nil, "", nil, err
+		return nil, err
 	}
 
-	return grants, nextPageToken, nil, nil
+	var userIDs []string
+	userOverrides := make(map[string][]client.ScheduleOverride)
+	for _, override := range overrides {
+		if !isAssignedShiftUser(override.User) {
+			continue
+		}
+
+		if _, ok := userOverrides[override.User.ID]; !ok {
+			userIDs = append(userIDs, override.User.ID)
+		}
+
+		userOverrides[override.User.ID] = append(userOverrides[override.User.ID], override)
+	}
+
+	var grants []*v2.Grant
+	for _, userID := range userIDs {
+		principalID, err := resource.NewResourceID(userResourceType, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create resource ID for user: %s", userID)
+		}
+
+		grants = append(grants, grant.NewGrant(
+			scheduleResource,
+			scheduleOverrideEntitlement,
+			principalID,
+			grant.WithGrantMetadata(overrideMetadata(userOverrides[userID])),
+		))
+	}
+
+	return grants, nil
+}
+
+// activeOverrides returns every override of the schedule that has not ended at now and starts
+// within overrideLookahead, read with list.
+func activeOverrides(ctx context.Context, list overrideLister, scheduleID string, now time.Time) ([]client.ScheduleOverride, error) {
+	l := ctxzap.Extract(ctx)
+
+	var active []client.ScheduleOverride
+	pageToken := ""
+	for {
+		overrides, nextPageToken, _, err := list(ctx, scheduleID, now, now.Add(overrideLookahead), client.PageOptions{
+			After:    pageToken,
+			PageSize: client.ItemsPerPage,
+		})
+		if err != nil {
+			l.Error("Error fetching schedule overrides", zap.Error(err), zap.String("schedule_id", scheduleID))
+			return nil, fmt.Errorf("error fetching overrides of schedule %s: %w", scheduleID, err)
+		}
+
+		for _, override := range overrides {
+			if override.EndAt.After(now) {
+				active = append(active, override)
+			}
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+
+		pageToken = nextPageToken
+	}
+
+	return active, nil
+}
+
+// overrideMetadata describes the overrides of a user, with start_at and end_at spanning all of them.
+func overrideMetadata(overrides []client.ScheduleOverride) map[string]interface{} {
+	startAt := overrides[0].StartAt
+	endAt := overrides[0].EndAt
+
+	details := make([]interface{}, 0, len(overrides))
+	for _, override := range overrides {
+		if override.StartAt.Before(startAt) {
+			startAt = override.StartAt
+		}
+
+		if override.EndAt.After(endAt) {
+			endAt = override.EndAt
+		}
+
+		details = append(details, map[string]interface{}{
+			"override_id": override.ID,
+			"rotation_id": override.RotationID,
+			"start_at":    override.StartAt.Format(time.RFC3339),
+			"end_at":      override.EndAt.Format(time.RFC3339),
+		})
+	}
+
+	return map[string]interface{}{
+		"start_at":  startAt.Format(time.RFC3339),
+		"end_at":    endAt.Format(time.RFC3339),
+		"overrides": details,
+	}
+}
+
+// Grant adds a user to a schedule through its Member entitlement. The user joins the first rotation
+// of the schedule in effect, unless they are on it already; grant the Member entitlement of a rotation
+// to pick another one.
+// Granting the Override entitlement instead puts the user on call from now for the configured
+// override duration, without changing the rotations.
+func (o *scheduleBuilder) Grant(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"incident.io: only users can be added to schedules",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, fmt.Errorf("incident.io: only users can be added to schedules")
+	}
+
+	if strings.HasSuffix(entitlement.Id, ":"+scheduleOverrideEntitlement) {
+		return o.grantOverride(ctx, principal, entitlement)
+	}
+
+	if !strings.HasSuffix(entitlement.Id, ":"+scheduleMemberEntitlement) {
+		return nil, fmt.Errorf("incident.io: the %s entitlement of a schedule cannot be granted, on call users follow the rotations", scheduleOnCallEntitlement)
+	}
+
+	scheduleID := entitlement.Resource.Id.Resource
+	changed, annos, err := updateRotationMembers(ctx, o.client, scheduleID, "", principal.Id.Resource, true)
+	if err != nil {
+		return nil, fmt.Errorf("error adding user %s to schedule %s: %w", principal.Id.Resource, scheduleID, err)
+	}
+
+	if !changed {
+		return annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	return annos, nil
+}
+
+// Revoke removes a user from every rotation of a schedule. Both Member and On_Call grants can be
+// revoked; removing an on call user also takes them off their current shift. Revoking the Override
+// entitlement deletes the user's current and upcoming overrides instead.
+func (o *scheduleBuilder) Revoke(ctx context.Context, grant *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	principal := grant.Principal
+	if principal.Id.ResourceType != userResourceType.Id {
+		l.Warn(
+			"incident.io: only users can be removed from schedules",
+			zap.String("principal_type", principal.Id.ResourceType),
+			zap.String("principal_id", principal.Id.Resource),
+		)
+		return nil, fmt.Errorf("incident.io: only users can be removed from schedules")
+	}
+
+	if strings.HasSuffix(grant.Entitlement.Id, ":"+scheduleOverrideEntitlement) {
+		return o.revokeOverride(ctx, principal, grant.Entitlement)
+	}
+
+	scheduleID := grant.Entitlement.Resource.Id.Resource
+	changed, annos, err := updateRotationMembers(ctx, o.client, scheduleID, "", principal.Id.Resource, false)
+	if err != nil {
+		return nil, fmt.Errorf("error removing user %s from schedule %s: %w", principal.Id.Resource, scheduleID, err)
+	}
+
+	if !changed {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	return annos, nil
+}
+
+// grantOverride creates an override putting the user on call for the first rotation of the schedule
+// in effect, from now until the configured override duration has passed. Users already covering the
+// schedule through an override keep it and no new override is created.
+func (o *scheduleBuilder) grantOverride(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	scheduleID := entitlement.Resource.Id.Resource
+	now := time.Now()
+
+	overrides, err := activeOverrides(ctx, o.client.ListScheduleOverridesForUpdate, scheduleID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, override := range overrides {
+		if override.User.ID == principal.Id.Resource && !override.StartAt.After(now) {
+			return annotations.New(&v2.GrantAlreadyExists{}), nil
+		}
+	}
+
+	schedule, _, _, err := o.client.GetScheduleForUpdate(ctx, scheduleID)
+	if err != nil {
+		l.Error("Error fetching schedule", zap.Error(err), zap.String("schedule_id", scheduleID))
+		return nil, fmt.Errorf("error fetching schedule %s: %w", scheduleID, err)
+	}
+
+	rotationID, ok := defaultRotation(schedule.Config.Rotation, now)
+	if !ok {
+		return nil, fmt.Errorf("incident.io: schedule %s has no rotation to override", scheduleID)
+	}
+
+	layerID := ""
+	for _, rotation := range effectiveRotations(schedule.Config.Rotation, now) {
+		if rotation.ID == rotationID && len(rotation.Layers) > 0 {
+			layerID = rotation.Layers[0].ID
+		}
+	}
+
+	_, annos, err := o.client.CreateScheduleOverride(ctx, client.CreateScheduleOverrideRequest{
+		ScheduleID: scheduleID,
+		RotationID: rotationID,
+		LayerID:    layerID,
+		User:       client.UserReference{ID: principal.Id.Resource},
+		StartAt:    now,
+		EndAt:      now.Add(o.overrideDuration),
+	})
+	if err != nil {
+		l.Error("Error creating schedule override", zap.Error(err), zap.String("schedule_id", scheduleID))
+		return nil, fmt.Errorf("error creating override of schedule %s for user %s: %w", scheduleID, principal.Id.Resource, err)
+	}
+
+	return annos, nil
+}
+
+// revokeOverride deletes the current and upcoming overrides of the user on the schedule, taking them
+// off call at once. Overrides that are already gone are skipped.
+func (o *scheduleBuilder) revokeOverride(ctx context.Context, principal *v2.Resource, entitlement *v2.Entitlement) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	scheduleID := entitlement.Resource.Id.Resource
+
+	overrides, err := activeOverrides(ctx, o.client.ListScheduleOverridesForUpdate, scheduleID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	deleted := false
+	for _, override := range overrides {
+		if override.User.ID != principal.Id.Resource {
+			continue
+		}
+
+		_, err := o.client.DeleteScheduleOverride(ctx, override.ID)
+		if hasAPIStatus(err, http.StatusNotFound) {
+			continue
+		}
+
+		if err != nil {
+			l.Error("Error deleting schedule override", zap.Error(err), zap.String("schedule_id", scheduleID), zap.String("override_id", override.ID))
+			return nil, fmt.Errorf("error deleting override %s of schedule %s: %w", override.ID, scheduleID, err)
+		}
+
+		deleted = true
+	}
+
+	if !deleted {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	return nil, nil
+}
+
+// updateRotationMembers adds a user to, or removes them from, the current and upcoming versions of a
+// rotation of the schedule and writes the schedule back. When rotationID is empty, the user is added to
+// the schedule's default rotation or removed from all its rotations. It reports whether the schedule
+// had to be changed, judged from a read of the schedule that bypasses the HTTP cache.
+func updateRotationMembers(ctx context.Context, c *client.APIClient, scheduleID, rotationID, userID string, add bool) (bool, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+	now := time.Now()
+
+	schedule, update, _, err := c.GetScheduleForUpdate(ctx, scheduleID)
+	if err != nil {
+		l.Error("Error fetching schedule", zap.Error(err), zap.String("schedule_id", scheduleID))
+		return false, nil, fmt.Errorf("error fetching schedule %s: %w", scheduleID, err)
+	}
+
+	if add && rotationID == "" {
+		var ok bool
+		rotationID, ok = defaultRotation(schedule.Config.Rotation, now)
+		if !ok {
+			return false, nil, fmt.Errorf("incident.io: schedule %s has no rotation to add user %s to", scheduleID, userID)
+		}
+	}
+
+	rotations, changed := withRotationMember(schedule.Config.Rotation, now, rotationID, userID, add)
+	if !changed {
+		return false, nil, nil
+	}
+
+	_, annos, err := c.UpdateSchedule(ctx, scheduleID, update, rotations)
+	if err != nil {
+		l.Error("Error updating schedule", zap.Error(err), zap.String("schedule_id", scheduleID))
+		return false, nil, fmt.Errorf("error updating schedule %s: %w", scheduleID, err)
+	}
+
+	return true, annos, nil
+}
+
+// withRotationMember returns a copy of rotations with userID added to or removed from the current and
+// upcoming versions of rotationID, or of every rotation when rotationID is empty. Superseded versions
+// are left untouched. It reports whether any version changed.
+func withRotationMember(rotations []client.Rotation, now time.Time, rotationID, userID string, add bool) ([]client.Rotation, bool) {
+	updated := slices.Clone(rotations)
+	activeVersions := activeRotationVersions(rotations, now)
+	changed := false
+
+	for index, rotation := range updated {
+		if !activeVersions[index] || (rotationID != "" && rotation.ID != rotationID) {
+			continue
+		}
+
+		hasUser := slices.ContainsFunc(rotation.Users, isShiftUser(userID))
+		switch {
+		case add && !hasUser:
+			updated[index].Users = append(slices.Clone(rotation.Users), client.ShiftUser{ID: userID})
+			changed = true
+		case !add && hasUser:
+			updated[index].Users = slices.DeleteFunc(slices.Clone(rotation.Users), isShiftUser(userID))
+			changed = true
+		}
+	}
+
+	return updated, changed
+}
+
+// defaultRotation returns the rotation users are added to when granted a schedule: the first
+// rotation in effect at now or, when none is, the first rotation of the schedule.
+func defaultRotation(rotations []client.Rotation, now time.Time) (string, bool) {
+	effective := effectiveRotations(rotations, now)
+	for _, rotation := range effective {
+		if rotationInEffect(rotation, now) {
+			return rotation.ID, true
+		}
+	}
+
+	if len(effective) > 0 {
+		return effective[0].ID, true
+	}
+
+	return "", false
+}
+
+// isShiftUser returns a matcher for the shift user with the given ID.
+func isShiftUser(userID string) func(client.ShiftUser) bool {
+	return func(user client.ShiftUser) bool {
+		return user.ID == userID
+	}
+}
+
+// scheduleProfile describes a schedule's timezone, the rotations in effect at now and
+// those of them nobody is on call for.
+func scheduleProfile(schedule client.Schedule, now time.Time) map[string]interface{} {
+	profile := map[string]interface{}{
+		"schedule_id": schedule.ID,
+		"timezone":    schedule.Timezone,
+	}
+
+	rotations := effectiveRotations(schedule.Config.Rotation, now)
+	profile["rotation_count"] = len(rotations)
+
+	var inEffect []string
+	for _, rotation := range rotations {
+		if rotationInEffect(rotation, now) {
+			inEffect = append(inEffect, rotation.Name)
+		}
+	}
+	if len(inEffect) > 0 {
+		profile["rotations_in_effect"] = strings.Join(inEffect, ", ")
+	}
+
+	uncovered := uncoveredRotations(schedule, now)
+	profile["uncovered_now"] = len(uncovered) > 0
+	if len(uncovered) > 0 {
+		profile["uncovered_rotations"] = strings.Join(uncovered, ", ")
+	}
+
+	return profile
+}
+
+// uncoveredRotations returns the names of the rotations in effect at now that nobody is on call for:
+// those whose current shifts are all unassigned or held by NOBODY and, as rotations restricted to
+// working intervals are expected to go uncovered outside of them, those without working intervals
+// that have no current shift at all.
+func uncoveredRotations(schedule client.Schedule, now time.Time) []string {
+	shifts := make(map[string]int)
+	covered := make(map[string]bool)
+	for _, shift := range schedule.CurrentShifts {
+		shifts[shift.RotationID]++
+		if isAssignedShiftUser(shift.User) {
+			covered[shift.RotationID] = true
+		}
+	}
+
+	var uncovered []string
+	for _, rotation := range effectiveRotations(schedule.Config.Rotation, now) {
+		if !rotationInEffect(rotation, now) || covered[rotation.ID] {
+			continue
+		}
+
+		if shifts[rotation.ID] > 0 || len(rotation.WorkingInterval) == 0 {
+			uncovered = append(uncovered, rotation.Name)
+		}
+	}
+
+	return uncovered
+}
+
+// upcomingGaps returns the final shifts of the schedule between now and the end of the coverage
+// gap window that are unassigned or held by NOBODY.
+func (o *scheduleBuilder) upcomingGaps(ctx context.Context, scheduleID string, now time.Time) ([]client.CurrentShift, error) {
+	shifts, _, err := o.client.ListScheduleEntries(ctx, scheduleID, now, now.Add(o.coverageGapWindow))
+	if err != nil {
+		return nil, fmt.Errorf("error fetching entries of schedule %s: %w", scheduleID, err)
+	}
+
+	var gaps []client.CurrentShift
+	for _, shift := range shifts {
+		if !isAssignedShiftUser(shift.User) {
+			gaps = append(gaps, shift)
+		}
+	}
+
+	return gaps, nil
+}
+
+// addGapProfile records the upcoming coverage gaps of a schedule, and the window they were looked for in, on its profile.
+func addGapProfile(profile map[string]interface{}, gaps []client.CurrentShift, window time.Duration) {
+	profile["coverage_gap_window"] = window.String()
+	profile["upcoming_gap_count"] = len(gaps)
+
+	if len(gaps) == 0 {
+		return
+	}
+
+	profile["next_gap_start_at"] = gaps[0].StartAt
+	profile["next_gap_end_at"] = gaps[0].EndAt
+
+	var windows []string
+	for _, gap := range gaps {
+		windows = append(windows, gap.StartAt+"/"+gap.EndAt)
+	}
+	profile["upcoming_gaps"] = strings.Join(windows, ", ")
+}
+
+// isAssignedShiftUser reports whether a shift or rotation slot is held by a real user rather
+// than being unassigned or given to the NOBODY placeholder.
+func isAssignedShiftUser(user client.ShiftUser) bool {
+	return user.ID != "" && user.ID != "NOBODY" && user.Email != ""
 }
 
 // createGrant generates a grant for a user with the specified role.
@@ -220,10 +695,14 @@ func createGrant(scheduleResource *v2.Resource, user client.User, role string) (
 	), nil
 }
 
-// newScheduleBuilder initializes a new schedule builder.
-func NewScheduleBuilder(c *client.APIClient) *scheduleBuilder {
+// NewScheduleBuilder initializes a new schedule builder. Overrides granted through it last overrideDuration,
+// and unassigned shifts starting within coverageGapWindow are reported on the schedule profiles; a zero
+// window turns the look-ahead off.
+func NewScheduleBuilder(c *client.APIClient, overrideDuration, coverageGapWindow time.Duration) *scheduleBuilder {
 	return &scheduleBuilder{
-		resourceType: scheduleResourceType,
-		client:       c,
+		resourceType:      scheduleResourceType,
+		client:            c,
+		overrideDuration:  overrideDuration,
+		coverageGapWindow: coverageGapWindow,
 	}
 }
@@ -0,0 +1,727 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/conductorone/baton-sdk/pkg/uhttp"
+)
+
+const (
+	primaryScheduleID   = "01JQ77YN7BRVRA81T9STQ41HB2"
+	secondaryScheduleID = "01JQ7818RVW6Q2CMR7TCKY4R6P"
+)
+
+// newScheduleTestClient returns a client whose transport serves each schedule, its overrides
+// and its entries from their own mock files.
+func newScheduleTestClient(t *testing.T, requested *[]string) *client.APIClient {
+	mocks := map[string]string{
+		primaryScheduleID:   "schedulePrimaryMock.json",
+		secondaryScheduleID: "scheduleSecondaryMock.json",
+		"schedules":         "schedulesMock.json",
+
+		"schedule_overrides?" + primaryScheduleID:   "scheduleOverridesPrimaryMock.json",
+		"schedule_overrides?" + secondaryScheduleID: "scheduleOverridesSecondaryMock.json",
+
+		"schedule_entries?" + primaryScheduleID:   "scheduleEntriesPrimaryMock.json",
+		"schedule_entries?" + secondaryScheduleID: "scheduleEntriesSecondaryMock.json",
+	}
+
+	mockTransport := &test.MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			*requested = append(*requested, req.URL.Path)
+
+			key := path.Base(req.URL.Path)
+			if key == "schedule_overrides" || key == "schedule_entries" {
+				key += "?" + req.URL.Query().Get("schedule_id")
+			}
+
+			fileName, ok := mocks[key]
+			if !ok {
+				return &http.Response{
+					StatusCode: http.StatusNotFound,
+					Header:     make(http.Header),
+					Body:       io.NopCloser(strings.NewReader("")),
+				}, nil
+			}
+
+			body, err := test.ReadFile(fileName)
+			if err != nil {
+				t.Fatalf("Error reading body: %s", err)
+			}
+
+			mockResponse := &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     make(http.Header),
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}
+			mockResponse.Header.Set("Content-Type", "application/json")
+
+			return mockResponse, nil
+		},
+	}
+
+	httpClient := &http.Client{Transport: mockTransport}
+
+	return client.NewClient("test", uhttp.NewBaseHttpClient(httpClient))
+}
+
+func scheduleResourceFor(id string) *v2.Resource {
+	return &v2.Resource{
+		Id: &v2.ResourceId{
+			ResourceType: scheduleResourceType.Id,
+			Resource:     id,
+		},
+	}
+}
+
+func TestScheduleBuilderGrants_OnlyRequestedSchedule(t *testing.T) {
+	testCases := []struct {
+		name       string
+		scheduleID string
+		expected   []string
+	}{
+		{
+			name:       "primary schedule",
+			scheduleID: primaryScheduleID,
+			expected: []string{
+				"01JPWQNM50YGKQYFJYW61BBPD7:On_Call",
+				"01JPWQP39ZE3X1NRHC3PJAWZVQ:Member",
+				"01JPWQP39ZE3X1NRHC3PJAWZVQ:Override",
+			},
+		},
+		{
+			name:       "secondary schedule",
+			scheduleID: secondaryScheduleID,
+			expected: []string{
+				"01JPWQP39ZE3X1NRHC3PJAWZVQ:Member",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requested []string
+			s := NewScheduleBuilder(newScheduleTestClient(t, &requested), defaultOverrideDuration, defaultCoverageGapWindow)
+
+			grants, nextToken, _, err := s.Grants(context.Background(), scheduleResourceFor(tc.scheduleID), &pagination.Token{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if nextToken != "" {
+				t.Errorf("Expected empty next page token, got %q", nextToken)
+			}
+
+			expectedRequests := []string{"/v2/schedules/" + tc.scheduleID, "/v2/schedule_overrides"}
+			if !slices.Equal(requested, expectedRequests) {
+				t.Errorf("Expected requests %v, got %v", expectedRequests, requested)
+			}
+
+			var got []string
+			for _, g := range grants {
+				if g.Entitlement.Resource.Id.Resource != tc.scheduleID {
+					t.Errorf("Grant for schedule %s emitted while syncing %s", g.Entitlement.Resource.Id.Resource, tc.scheduleID)
+				}
+
+				prefix := strings.Join([]string{scheduleResourceType.Id, tc.scheduleID}, ":") + ":"
+				if !strings.HasPrefix(g.Entitlement.Id, prefix) {
+					t.Errorf("Expected an entitlement of schedule %s, got %s", tc.scheduleID, g.Entitlement.Id)
+				}
+
+				got = append(got, g.Principal.Id.Resource+":"+strings.TrimPrefix(g.Entitlement.Id, prefix))
+			}
+
+			if !slices.Equal(got, tc.expected) {
+				t.Errorf("Expected grants %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestScheduleBuilderGrants_UnknownSchedule(t *testing.T) {
+	var requested []string
+	s := NewScheduleBuilder(newScheduleTestClient(t, &requested), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	_, _, _, err := s.Grants(context.Background(), scheduleResourceFor("unknown"), &pagination.Token{})
+	if err == nil {
+		t.Fatal("Expected an error for an unknown schedule")
+	}
+}
+
+// newScheduleProvisioningTestClient serves the primary schedule and its overrides, letting modify change
+// what each GET of the schedule returns to simulate edits made elsewhere, and answers schedule updates and
+// created or deleted overrides. The schedule is served as is when modify is nil.
+func newScheduleProvisioningTestClient(t *testing.T, modify func(get int, schedule *client.Schedule), requests *[]test.Request) *client.APIClient {
+	body, err := test.ReadFile("schedulePrimaryMock.json")
+	if err != nil {
+		t.Fatalf("Error reading body: %s", err)
+	}
+
+	gets := 0
+	getSchedule := func(*http.Request, []byte) (int, string) {
+		gets++
+		if modify == nil {
+			return http.StatusOK, body
+		}
+
+		var res client.GetScheduleResponse
+		if err := json.Unmarshal([]byte(body), &res); err != nil {
+			t.Fatalf("Error decoding schedule mock: %s", err)
+		}
+
+		modify(gets, &res.Schedule)
+
+		encoded, err := json.Marshal(res)
+		if err != nil {
+			t.Fatalf("Error encoding schedule: %s", err)
+		}
+
+		return http.StatusOK, string(encoded)
+	}
+
+	createOverride := func(_ *http.Request, reqBody []byte) (int, string) {
+		var override client.CreateScheduleOverrideRequest
+		if err := json.Unmarshal(reqBody, &override); err != nil {
+			t.Fatalf("Error decoding override body: %s", err)
+		}
+
+		res, err := json.Marshal(client.CreateScheduleOverrideResponse{Override: client.ScheduleOverride{
+			ID:         "01JQ7A0VERR1DE0000000000C1",
+			ScheduleID: override.ScheduleID,
+			RotationID: override.RotationID,
+			LayerID:    override.LayerID,
+			User:       client.ShiftUser{ID: override.User.ID},
+			StartAt:    override.StartAt,
+			EndAt:      override.EndAt,
+		}})
+		if err != nil {
+			t.Fatalf("Error encoding override: %s", err)
+		}
+
+		return http.StatusCreated, string(res)
+	}
+
+	return test.NewRoutingTestClient(map[string]string{
+		"PUT /v2/schedules/" + primaryScheduleID: "schedulePrimaryMock.json",
+		"GET /v2/schedule_overrides":             "scheduleOverridesPrimaryMock.json",
+	},
+		test.WithHandler("GET /v2/schedules/"+primaryScheduleID, getSchedule),
+		test.WithHandler("POST /v2/schedule_overrides", createOverride),
+		test.WithStatus("DELETE /v2/schedule_overrides/01JQ7A0VERR1DE0000000000A1", http.StatusNoContent),
+		test.WithStatus("DELETE /v2/schedule_overrides/01JQ7A0VERR1DE0000000000A2", http.StatusNoContent),
+		test.WithRecorder(requests),
+	)
+}
+
+// scheduleUpdates decodes the bodies of the schedule updates among requests.
+func scheduleUpdates(t *testing.T, requests []test.Request) []client.UpdateScheduleRequest {
+	var updates []client.UpdateScheduleRequest
+	for _, req := range requests {
+		if req.Method != http.MethodPut {
+			continue
+		}
+
+		var update client.UpdateScheduleRequest
+		if err := json.Unmarshal(req.Body, &update); err != nil {
+			t.Fatalf("Error decoding update body: %s", err)
+		}
+		updates = append(updates, update)
+	}
+
+	return updates
+}
+
+// createdOverrides decodes the bodies of the overrides created among requests.
+func createdOverrides(t *testing.T, requests []test.Request) []client.CreateScheduleOverrideRequest {
+	var overrides []client.CreateScheduleOverrideRequest
+	for _, req := range requests {
+		if req.Method != http.MethodPost || req.Path != "/v2/schedule_overrides" {
+			continue
+		}
+
+		var override client.CreateScheduleOverrideRequest
+		if err := json.Unmarshal(req.Body, &override); err != nil {
+			t.Fatalf("Error decoding override body: %s", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides
+}
+
+func scheduleEntitlement(role string) *v2.Entitlement {
+	return &v2.Entitlement{
+		Id:       strings.Join([]string{scheduleResourceType.Id, primaryScheduleID, role}, ":"),
+		Resource: scheduleResourceFor(primaryScheduleID),
+	}
+}
+
+// rotationUsers returns the IDs of the users of each version of a rotation in an update.
+func rotationUsers(update client.UpdateScheduleRequest, rotationID string) [][]string {
+	var versions [][]string
+	for _, rotation := range update.Schedule.Config.Rotations {
+		if rotation.ID != rotationID {
+			continue
+		}
+
+		var users []string
+		for _, user := range rotation.Users {
+			users = append(users, user.ID)
+		}
+		versions = append(versions, users)
+	}
+
+	return versions
+}
+
+func TestScheduleBuilderGrant(t *testing.T) {
+	const newUserID = "01JPWQZZ4NEWUSER0000000000"
+
+	var requests []test.Request
+	s := NewScheduleBuilder(newScheduleProvisioningTestClient(t, nil, &requests), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	annos, err := s.Grant(context.Background(), userPrincipal(newUserID), scheduleEntitlement(scheduleMemberEntitlement))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if annos.Contains(&v2.GrantAlreadyExists{}) {
+		t.Error("Expected the user to be added")
+	}
+
+	updates := scheduleUpdates(t, requests)
+	if len(updates) != 1 {
+		t.Fatalf("Expected 1 schedule update, got %d", len(updates))
+	}
+
+	for _, users := range rotationUsers(updates[0], weeklyRotationID) {
+		if !slices.Contains(users, newUserID) {
+			t.Errorf("Expected every current and upcoming Weekly version to include the user, got %v", users)
+		}
+	}
+
+	for _, users := range rotationUsers(updates[0], weekendRotationID) {
+		if slices.Contains(users, newUserID) {
+			t.Errorf("Expected the Weekend rotation to be left unchanged, got %v", users)
+		}
+	}
+}
+
+func TestScheduleBuilderGrant_AlreadyMember(t *testing.T) {
+	var requests []test.Request
+	s := NewScheduleBuilder(newScheduleProvisioningTestClient(t, nil, &requests), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	annos, err := s.Grant(context.Background(), userPrincipal("01JPWQP39ZE3X1NRHC3PJAWZVQ"), scheduleEntitlement(scheduleMemberEntitlement))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !annos.Contains(&v2.GrantAlreadyExists{}) {
+		t.Error("Expected a GrantAlreadyExists annotation")
+	}
+
+	updates := scheduleUpdates(t, requests)
+	if len(updates) != 0 {
+		t.Errorf("Expected no schedule update, got %d", len(updates))
+	}
+}
+
+func TestScheduleBuilderGrant_KeepsUnmodelledFields(t *testing.T) {
+	var requests []test.Request
+	s := NewScheduleBuilder(newScheduleProvisioningTestClient(t, nil, &requests), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	_, err := s.Grant(context.Background(), userPrincipal("01JPWQZZ4NEWUSER0000000000"), scheduleEntitlement(scheduleMemberEntitlement))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var body struct {
+		Schedule map[string]json.RawMessage `json:"schedule"`
+	}
+	for _, req := range requests {
+		if req.Method == http.MethodPut {
+			if err := json.Unmarshal(req.Body, &body); err != nil {
+				t.Fatalf("Error decoding update body: %s", err)
+			}
+		}
+	}
+
+	if string(body.Schedule["team_ids"]) != `["01JQ77TEAMPAYMENTS000000T1"]` || string(body.Schedule["holidays_public_config"]) != `{"country_codes":["GB"]}` {
+		t.Errorf("Expected the fields the client does not model to be written back, got %v", body.Schedule)
+	}
+
+	for _, field := range []string{"id", "created_at", "current_shifts"} {
+		if _, ok := body.Schedule[field]; ok {
+			t.Errorf("Expected the read-only field %s to be left out of the update", field)
+		}
+	}
+}
+
+func TestScheduleBuilderGrant_OnCall(t *testing.T) {
+	var requests []test.Request
+	s := NewScheduleBuilder(newScheduleProvisioningTestClient(t, nil, &requests), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	_, err := s.Grant(context.Background(), userPrincipal("01JPWQP39ZE3X1NRHC3PJAWZVQ"), scheduleEntitlement(scheduleOnCallEntitlement))
+	if err == nil {
+		t.Fatal("Expected an error granting On_Call")
+	}
+}
+
+func TestScheduleBuilderRevoke(t *testing.T) {
+	const userID = "01JPWQP39ZE3X1NRHC3PJAWZVQ"
+
+	var requests []test.Request
+	s := NewScheduleBuilder(newScheduleProvisioningTestClient(t, nil, &requests), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	grant := &v2.Grant{
+		Entitlement: scheduleEntitlement(scheduleMemberEntitlement),
+		Principal:   userPrincipal(userID),
+	}
+
+	annos, err := s.Revoke(context.Background(), grant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if annos.Contains(&v2.GrantAlreadyRevoked{}) {
+		t.Error("Expected the user to be removed")
+	}
+
+	updates := scheduleUpdates(t, requests)
+	if len(updates) != 1 {
+		t.Fatalf("Expected 1 schedule update, got %d", len(updates))
+	}
+
+	for _, rotationID := range []string{weeklyRotationID, weekendRotationID} {
+		for _, users := range rotationUsers(updates[0], rotationID) {
+			if slices.Contains(users, userID) {
+				t.Errorf("Expected the user to be removed from rotation %s, got %v", rotationID, users)
+			}
+		}
+	}
+
+	if users := rotationUsers(updates[0], weeklyRotationID); len(users) == 0 || !slices.Contains(users[0], "01JPWQNM50YGKQYFJYW61BBPD7") {
+		t.Errorf("Expected the other Weekly users to be kept, got %v", users)
+	}
+
+	expected := []string{"GET /v2/schedules/" + primaryScheduleID + " test", "PUT /v2/schedules/" + primaryScheduleID + " test"}
+	if lines := requestLines(requests); !slices.Equal(lines, expected) {
+		t.Errorf("Expected the schedule to be read once before the update, got %v", lines)
+	}
+}
+
+func TestScheduleBuilderRevoke_NotMember(t *testing.T) {
+	var requests []test.Request
+	s := NewScheduleBuilder(newScheduleProvisioningTestClient(t, nil, &requests), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	grant := &v2.Grant{
+		Entitlement: scheduleEntitlement(scheduleMemberEntitlement),
+		Principal:   userPrincipal("01JPWQZZ4NEWUSER0000000000"),
+	}
+
+	annos, err := s.Revoke(context.Background(), grant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !annos.Contains(&v2.GrantAlreadyRevoked{}) {
+		t.Error("Expected a GrantAlreadyRevoked annotation")
+	}
+
+	updates := scheduleUpdates(t, requests)
+	if len(updates) != 0 {
+		t.Errorf("Expected no schedule update, got %d", len(updates))
+	}
+}
+
+func TestScheduleBuilderGrant_AfterCachedRead(t *testing.T) {
+	const userID = "01JPWQP39ZE3X1NRHC3PJAWZVQ"
+
+	var requests []test.Request
+	modify := func(get int, schedule *client.Schedule) {
+		// The user is removed from the schedule after it was synced.
+		if get > 1 {
+			for index, rotation := range schedule.Config.Rotation {
+				schedule.Config.Rotation[index].Users = slices.DeleteFunc(rotation.Users, isShiftUser(userID))
+			}
+		}
+	}
+	s := NewScheduleBuilder(newScheduleProvisioningTestClient(t, modify, &requests), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	if _, _, _, err := s.Grants(context.Background(), scheduleResourceFor(primaryScheduleID), &pagination.Token{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	annos, err := s.Grant(context.Background(), userPrincipal(userID), scheduleEntitlement(scheduleMemberEntitlement))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if annos.Contains(&v2.GrantAlreadyExists{}) {
+		t.Error("Expected the user to be added again")
+	}
+
+	updates := scheduleUpdates(t, requests)
+	if len(updates) != 1 {
+		t.Fatalf("Expected 1 schedule update, got %d", len(updates))
+	}
+
+	if users := rotationUsers(updates[0], weeklyRotationID); len(users) == 0 || !slices.Contains(users[0], userID) {
+		t.Errorf("Expected the user to be added to the Weekly rotation, got %v", users)
+	}
+}
+
+func TestScheduleBuilderGrants_OverrideMetadata(t *testing.T) {
+	var requested []string
+	s := NewScheduleBuilder(newScheduleTestClient(t, &requested), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	grants, _, _, err := s.Grants(context.Background(), scheduleResourceFor(primaryScheduleID), &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, g := range grants {
+		if !strings.HasSuffix(g.Entitlement.Id, ":"+scheduleOverrideEntitlement) {
+			continue
+		}
+
+		metadata := &v2.GrantMetadata{}
+		annos := annotations.Annotations(g.Annotations)
+		ok, err := annos.Pick(metadata)
+		if err != nil || !ok {
+			t.Fatalf("Expected grant metadata on the override grant, got %v", err)
+		}
+
+		values := metadata.Metadata.AsMap()
+		if values["start_at"] != "2099-01-01T09:00:00Z" || values["end_at"] != "2099-02-02T09:00:00Z" {
+			t.Errorf("Expected the override window to span both overrides, got %v - %v", values["start_at"], values["end_at"])
+		}
+
+		if overrides, _ := values["overrides"].([]interface{}); len(overrides) != 2 {
+			t.Errorf("Expected 2 overrides in the metadata, got %v", values["overrides"])
+		}
+	}
+}
+
+func TestScheduleBuilderGrant_Override(t *testing.T) {
+	const newUserID = "01JPWQZZ4NEWUSER0000000000"
+
+	var requests []test.Request
+	s := NewScheduleBuilder(newScheduleProvisioningTestClient(t, nil, &requests), 2*time.Hour, defaultCoverageGapWindow)
+
+	before := time.Now()
+	_, err := s.Grant(context.Background(), userPrincipal(newUserID), scheduleEntitlement(scheduleOverrideEntitlement))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	updates := scheduleUpdates(t, requests)
+	if len(updates) != 0 {
+		t.Errorf("Expected the rotations to be left unchanged, got %d updates", len(updates))
+	}
+
+	overrides := createdOverrides(t, requests)
+	if len(overrides) != 1 {
+		t.Fatalf("Expected 1 override to be created, got %d", len(overrides))
+	}
+
+	override := overrides[0]
+	if override.User.ID != newUserID || override.RotationID != weeklyRotationID || override.LayerID != "01JQ77YN7BRVRA81T9STQ4LAY1" {
+		t.Errorf("Unexpected override %+v", override)
+	}
+
+	if override.StartAt.Before(before.Add(-time.Second)) || override.EndAt.Sub(override.StartAt) != 2*time.Hour {
+		t.Errorf("Expected a two hour override starting now, got %s - %s", override.StartAt, override.EndAt)
+	}
+}
+
+func TestScheduleBuilderRevoke_Override(t *testing.T) {
+	var requests []test.Request
+	s := NewScheduleBuilder(newScheduleProvisioningTestClient(t, nil, &requests), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	grant := &v2.Grant{
+		Entitlement: scheduleEntitlement(scheduleOverrideEntitlement),
+		Principal:   userPrincipal("01JPWQP39ZE3X1NRHC3PJAWZVQ"),
+	}
+
+	annos, err := s.Revoke(context.Background(), grant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if annos.Contains(&v2.GrantAlreadyRevoked{}) {
+		t.Error("Expected the overrides to be deleted")
+	}
+
+	var deleted []string
+	for _, req := range requests {
+		switch {
+		case req.Method == http.MethodDelete:
+			deleted = append(deleted, path.Base(req.Path))
+		case req.Method == http.MethodGet && req.Path == "/v2/schedule_overrides":
+			query, err := url.ParseQuery(req.Query)
+			if err != nil || query.Get("start_at") == "" || query.Get("end_at") == "" {
+				t.Errorf("Expected the overrides to be listed over a window, got query %q", req.Query)
+			}
+		}
+	}
+
+	expected := []string{"01JQ7A0VERR1DE0000000000A1", "01JQ7A0VERR1DE0000000000A2"}
+	if !slices.Equal(deleted, expected) {
+		t.Errorf("Expected the user's upcoming overrides %v to be deleted, got %v", expected, deleted)
+	}
+
+	if updates := scheduleUpdates(t, requests); len(updates) != 0 {
+		t.Errorf("Expected no schedule update, got %d", len(updates))
+	}
+}
+
+func TestScheduleBuilderRevoke_OverrideEnded(t *testing.T) {
+	var requests []test.Request
+	s := NewScheduleBuilder(newScheduleProvisioningTestClient(t, nil, &requests), defaultOverrideDuration, defaultCoverageGapWindow)
+
+	// The only override of this user ended in 2025.
+	grant := &v2.Grant{
+		Entitlement: scheduleEntitlement(scheduleOverrideEntitlement),
+		Principal:   userPrincipal("01JPWQNM50YGKQYFJYW61BBPD7"),
+	}
+
+	annos, err := s.Revoke(context.Background(), grant)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !annos.Contains(&v2.GrantAlreadyRevoked{}) {
+		t.Error("Expected a GrantAlreadyRevoked annotation")
+	}
+
+	for _, req := range requests {
+		if req.Method == http.MethodDelete {
+			t.Errorf("Expected no override to be deleted, got %s", req.Path)
+		}
+	}
+}
+
+func TestScheduleBuilderList_CoverageGaps(t *testing.T) {
+	testCases := []struct {
+		name     string
+		window   time.Duration
+		expected map[string]map[string]interface{}
+		absent   []string
+	}{
+		{
+			name:   "look-ahead window",
+			window: defaultCoverageGapWindow,
+			expected: map[string]map[string]interface{}{
+				primaryScheduleID: {
+					"uncovered_now":       false,
+					"coverage_gap_window": "168h0m0s",
+					"upcoming_gap_count":  float64(2),
+					"next_gap_start_at":   "2099-01-12T09:00:00Z",
+					"next_gap_end_at":     "2099-01-13T09:00:00Z",
+				},
+				secondaryScheduleID: {
+					"uncovered_now":       true,
+					"uncovered_rotations": "Weekend",
+					"upcoming_gap_count":  float64(0),
+				},
+			},
+		},
+		{
+			name:   "look-ahead disabled",
+			window: 0,
+			expected: map[string]map[string]interface{}{
+				secondaryScheduleID: {
+					"uncovered_now":       true,
+					"uncovered_rotations": "Weekend",
+				},
+			},
+			absent: []string{"coverage_gap_window", "upcoming_gap_count"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requested []string
+			s := NewScheduleBuilder(newScheduleTestClient(t, &requested), defaultOverrideDuration, tc.window)
+
+			resources, _, _, err := s.List(context.Background(), nil, &pagination.Token{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			for _, res := range resources {
+				groupTrait, err := resource.GetGroupTrait(res)
+				if err != nil {
+					t.Fatalf("Expected a group trait on schedule %s, got %v", res.Id.Resource, err)
+				}
+
+				profile := groupTrait.GetProfile().AsMap()
+				for key, value := range tc.expected[res.Id.Resource] {
+					if profile[key] != value {
+						t.Errorf("Expected %s=%v on schedule %s, got %v", key, value, res.Id.Resource, profile[key])
+					}
+				}
+
+				for _, key := range tc.absent {
+					if _, ok := profile[key]; ok {
+						t.Errorf("Expected no %s on schedule %s", key, res.Id.Resource)
+					}
+				}
+			}
+
+			if tc.window == 0 && slices.Contains(requested, "/v2/schedule_entries") {
+				t.Error("Expected no schedule entries to be requested without a look-ahead window")
+			}
+		})
+	}
+}
+
+func TestScheduleBuilderList_CoverageGapsUnavailable(t *testing.T) {
+	c := test.NewRoutingTestClient(map[string]string{
+		"/v2/schedules": "schedulesMock.json",
+	}, test.WithStatus("/v2/schedule_entries", http.StatusForbidden))
+	s := NewScheduleBuilder(c, defaultOverrideDuration, defaultCoverageGapWindow)
+
+	resources, _, _, err := s.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected the schedules to be listed without their coverage gaps, got %v", err)
+	}
+
+	if len(resources) == 0 {
+		t.Fatal("Expected schedules to be listed")
+	}
+
+	for _, res := range resources {
+		groupTrait, err := resource.GetGroupTrait(res)
+		if err != nil {
+			t.Fatalf("Expected a group trait on schedule %s, got %v", res.Id.Resource, err)
+		}
+
+		profile := groupTrait.GetProfile().AsMap()
+		for _, key := range []string{"coverage_gap_window", "upcoming_gap_count", "next_gap_start_at"} {
+			if _, ok := profile[key]; ok {
+				t.Errorf("Expected no %s on schedule %s", key, res.Id.Resource)
+			}
+		}
+
+		if _, ok := profile["uncovered_now"]; !ok {
+			t.Errorf("Expected the rest of the profile on schedule %s, got %v", res.Id.Resource, profile)
+		}
+	}
+}
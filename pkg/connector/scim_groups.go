@@ -0,0 +1,211 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const scimGroupMemberEntitlement = "member"
+
+// scimGroupBuilder syncs the groups an identity provider pushes to incident.io over SCIM. Groups
+// can be mapped to base and custom roles in incident.io, so their members hold those roles.
+type scimGroupBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.APIClient
+}
+
+// ResourceType returns the resource type associated with SCIM groups.
+func (o *scimGroupBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return scimGroupResourceType
+}
+
+// List retrieves a page of SCIM groups.
+func (o *scimGroupBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	bag, pageToken, err := getToken(pToken, scimGroupResourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	groups, nextPageToken, annos, err := o.client.ListSCIMGroups(ctx, client.PageOptions{
+		After:    pageToken,
+		PageSize: pToken.Size,
+	})
+	if err != nil {
+		l.Error("Error fetching SCIM groups", zap.Error(err))
+		return nil, "", nil, fmt.Errorf("error fetching SCIM groups: %w", err)
+	}
+
+	var resources []*v2.Resource
+	for _, group := range groups {
+		profile := map[string]interface{}{
+			"scim_group_id": group.ID,
+			"external_id":   group.ExternalID,
+		}
+
+		groupResource, err := resource.NewGroupResource(
+			group.DisplayName,
+			scimGroupResourceType,
+			group.ID,
+			[]resource.GroupTraitOption{resource.WithGroupProfile(profile)},
+			resource.WithParentResourceID(parentResourceID),
+		)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error creating SCIM group resource: %w", err)
+		}
+
+		resources = append(resources, groupResource)
+	}
+
+	err = bag.Next(nextPageToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPageToken, err = bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return resources, nextPageToken, annos, nil
+}
+
+// Entitlements returns the member entitlement of a SCIM group.
+func (o *scimGroupBuilder) Entitlements(_ context.Context, groupResource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			groupResource,
+			scimGroupMemberEntitlement,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s SCIM Group Member", groupResource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Member of the %s SCIM group", groupResource.DisplayName)),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns a member grant for every user in the SCIM group.
+func (o *scimGroupBuilder) Grants(ctx context.Context, groupResource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	group, annos, err := o.client.GetSCIMGroup(ctx, groupResource.Id.Resource)
+	if err != nil {
+		l.Error("Error fetching SCIM group", zap.Error(err), zap.String("scim_group_id", groupResource.Id.Resource))
+		return nil, "", nil, fmt.Errorf("error fetching SCIM group %s: %w", groupResource.Id.Resource, err)
+	}
+
+	var grants []*v2.Grant
+	seenUsers := make(map[string]bool)
+	for _, member := range group.Members {
+		if member.Value == "" || seenUsers[member.Value] {
+			continue
+		}
+
+		seenUsers[member.Value] = true
+
+		principalID, err := resource.NewResourceID(userResourceType, member.Value)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create resource ID for user: %s", member.Value)
+		}
+
+		grants = append(grants, grant.NewGrant(groupResource, scimGroupMemberEntitlement, principalID))
+	}
+
+	return grants, "", annos, nil
+}
+
+// Grant adds a user to a SCIM group.
+func (o *scimGroupBuilder) Grant(ctx context.Context, principal *v2.Resource, ent *v2.Entitlement) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if principal.Id.ResourceType != userResourceType.Id {
+		return nil, fmt.Errorf("incident.io: only users can be granted SCIM group membership")
+	}
+
+	groupID := ent.Resource.Id.Resource
+	userID := principal.Id.Resource
+
+	isMember, err := o.isMember(ctx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if isMember {
+		return annotations.New(&v2.GrantAlreadyExists{}), nil
+	}
+
+	annos, err := o.client.AddSCIMGroupMember(ctx, groupID, userID)
+	if err != nil {
+		l.Error("Error adding SCIM group member", zap.Error(err), zap.String("scim_group_id", groupID), zap.String("user_id", userID))
+		return nil, fmt.Errorf("error adding user %s to SCIM group %s: %w", userID, groupID, err)
+	}
+
+	return annos, nil
+}
+
+// Revoke removes a user from a SCIM group.
+func (o *scimGroupBuilder) Revoke(ctx context.Context, g *v2.Grant) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if g.Principal.Id.ResourceType != userResourceType.Id {
+		return nil, fmt.Errorf("incident.io: only users can be revoked SCIM group membership")
+	}
+
+	groupID := g.Entitlement.Resource.Id.Resource
+	userID := g.Principal.Id.Resource
+
+	isMember, err := o.isMember(ctx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isMember {
+		return annotations.New(&v2.GrantAlreadyRevoked{}), nil
+	}
+
+	annos, err := o.client.RemoveSCIMGroupMember(ctx, groupID, userID)
+	if err != nil {
+		l.Error("Error removing SCIM group member", zap.Error(err), zap.String("scim_group_id", groupID), zap.String("user_id", userID))
+		return nil, fmt.Errorf("error removing user %s from SCIM group %s: %w", userID, groupID, err)
+	}
+
+	return annos, nil
+}
+
+// isMember reports whether a user currently belongs to a SCIM group, reading the group without the
+// HTTP cache so that a membership changed earlier in the same run is seen.
+func (o *scimGroupBuilder) isMember(ctx context.Context, groupID, userID string) (bool, error) {
+	l := ctxzap.Extract(ctx)
+
+	group, _, err := o.client.GetSCIMGroupForUpdate(ctx, groupID)
+	if err != nil {
+		l.Error("Error fetching SCIM group", zap.Error(err), zap.String("scim_group_id", groupID))
+		return false, fmt.Errorf("error fetching SCIM group %s: %w", groupID, err)
+	}
+
+	for _, member := range group.Members {
+		if member.Value == userID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// NewSCIMGroupBuilder initializes a new SCIM group builder.
+func NewSCIMGroupBuilder(c *client.APIClient) *scimGroupBuilder {
+	return &scimGroupBuilder{
+		resourceType: scimGroupResourceType,
+		client:       c,
+	}
+}
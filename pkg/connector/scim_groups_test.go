@@ -0,0 +1,279 @@
+package connector
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const onCallSCIMGroupID = "01JQ4SCIMGRP0NCALL0000000"
+
+// newSCIMGroupTestClient serves the SCIM group mocks, answers PATCH requests with a 204 and
+// records every request.
+func newSCIMGroupTestClient(requests *[]test.Request) *client.APIClient {
+	return test.NewRoutingTestClient(
+		map[string]string{
+			"GET /scim/v2/Groups":                      "scimGroupsMock.json",
+			"GET /scim/v2/Groups/" + onCallSCIMGroupID: "scimGroupMock.json",
+		},
+		test.WithStatus("PATCH /scim/v2/Groups/"+onCallSCIMGroupID, http.StatusNoContent),
+		test.WithRecorder(requests),
+		test.WithClientOptions(client.WithSCIMToken("scim")),
+	)
+}
+
+// scimGroupPatches decodes the bodies of the PATCH requests among requests.
+func scimGroupPatches(t *testing.T, requests []test.Request) []client.SCIMPatchRequest {
+	var patches []client.SCIMPatchRequest
+	for _, req := range requests {
+		if req.Token != "scim" {
+			t.Errorf("Expected %s %s to be authenticated with the SCIM token, got %q", req.Method, req.Path, req.Token)
+		}
+
+		if req.Method != http.MethodPatch {
+			continue
+		}
+
+		var patch client.SCIMPatchRequest
+		if err := json.Unmarshal(req.Body, &patch); err != nil {
+			t.Fatalf("Error decoding patch body: %s", err)
+		}
+		patches = append(patches, patch)
+	}
+
+	return patches
+}
+
+func scimGroupEntitlement(groupID string) *v2.Entitlement {
+	return &v2.Entitlement{
+		Id:       "scim_group:" + groupID + ":" + scimGroupMemberEntitlement,
+		Resource: &v2.Resource{Id: &v2.ResourceId{ResourceType: scimGroupResourceType.Id, Resource: groupID}},
+	}
+}
+
+func TestSCIMGroupBuilderList(t *testing.T) {
+	var requests []test.Request
+	g := NewSCIMGroupBuilder(newSCIMGroupTestClient(&requests))
+
+	resources, nextPageToken, _, err := g.List(context.Background(), nil, &pagination.Token{Size: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 SCIM groups, got %d", len(resources))
+	}
+
+	if resources[0].DisplayName != "On-call Responders" || resources[0].Id.Resource != onCallSCIMGroupID {
+		t.Errorf("Unexpected SCIM group: %v", resources[0])
+	}
+
+	groupTrait, err := resource.GetGroupTrait(resources[0])
+	if err != nil {
+		t.Fatalf("Expected a group trait, got %v", err)
+	}
+
+	if groupTrait.GetProfile().AsMap()["external_id"] != "00g1oncall" {
+		t.Errorf("Unexpected profile: %v", groupTrait.GetProfile().AsMap())
+	}
+
+	if nextPageToken != "" {
+		t.Errorf("Expected no next page once every group is read, got %q", nextPageToken)
+	}
+
+	if len(requests) != 1 || requests[0].Query != "count=2&startIndex=1" || requests[0].Token != "scim" {
+		t.Errorf("Unexpected list requests: %+v", requests)
+	}
+}
+
+func TestIncidentClient_ListSCIMGroups_Pagination(t *testing.T) {
+	var requests []test.Request
+	c := test.NewRoutingTestClient(nil,
+		test.WithHandler("GET /scim/v2/Groups", func(*http.Request, []byte) (int, string) {
+			return http.StatusOK, `{"totalResults":3,"startIndex":1,"itemsPerPage":2,"Resources":[{"id":"a"},{"id":"b"}]}`
+		}),
+		test.WithRecorder(&requests),
+		test.WithClientOptions(client.WithSCIMToken("scim")),
+	)
+
+	groups, nextPageToken, _, err := c.ListSCIMGroups(context.Background(), client.PageOptions{PageSize: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(groups) != 2 || nextPageToken != "3" {
+		t.Errorf("Expected 2 groups and a next page starting at 3, got %d groups and %q", len(groups), nextPageToken)
+	}
+
+	_, _, _, err = c.ListSCIMGroups(context.Background(), client.PageOptions{After: "not-a-number"})
+	if err == nil {
+		t.Error("Expected an error for an invalid page token")
+	}
+
+	if len(requests) != 1 || requests[0].Query != "count=2&startIndex=1" {
+		t.Errorf("Unexpected list requests: %+v", requests)
+	}
+}
+
+func TestIncidentClient_ListSCIMGroups_MissingSCIMToken(t *testing.T) {
+	c := client.NewClient("test", nil)
+
+	_, _, _, err := c.ListSCIMGroups(context.Background(), client.PageOptions{})
+	if !errors.Is(err, client.ErrSCIMTokenMissing) {
+		t.Fatalf("Expected ErrSCIMTokenMissing, got %v", err)
+	}
+}
+
+func TestSCIMGroupBuilderGrants(t *testing.T) {
+	g := NewSCIMGroupBuilder(newSCIMGroupTestClient(nil))
+
+	groupResource := &v2.Resource{Id: &v2.ResourceId{ResourceType: scimGroupResourceType.Id, Resource: onCallSCIMGroupID}}
+
+	grants, _, _, err := g.Grants(context.Background(), groupResource, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(grants) != 1 {
+		t.Fatalf("Expected one grant for the duplicated member, got %d", len(grants))
+	}
+
+	if grants[0].Principal.Id.Resource != "01JPWQNM50YGKQYFJYW61BBPD7" || grants[0].Entitlement.Id != scimGroupEntitlement(onCallSCIMGroupID).Id {
+		t.Errorf("Unexpected grant: %v", grants[0])
+	}
+}
+
+func TestSCIMGroupBuilderGrant(t *testing.T) {
+	var requests []test.Request
+	g := NewSCIMGroupBuilder(newSCIMGroupTestClient(&requests))
+
+	annos, err := g.Grant(context.Background(), userPrincipal("01JPWQNM50YGKQYFJYW61BBPD7"), scimGroupEntitlement(onCallSCIMGroupID))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !annos.Contains(&v2.GrantAlreadyExists{}) {
+		t.Error("Expected GrantAlreadyExists annotation")
+	}
+
+	_, err = g.Grant(context.Background(), userPrincipal("01JPWQP39ZE3X1NRHC3PJAWZVQ"), scimGroupEntitlement(onCallSCIMGroupID))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []client.SCIMPatchRequest{
+		{
+			Schemas: []string{client.SCIMPatchOpSchema},
+			Operations: []client.SCIMPatchOperation{
+				{Op: "add", Path: "members", Value: []interface{}{map[string]interface{}{"value": "01JPWQP39ZE3X1NRHC3PJAWZVQ"}}},
+			},
+		},
+	}
+
+	if patches := scimGroupPatches(t, requests); !reflect.DeepEqual(patches, expected) {
+		t.Errorf("Unexpected patches: got %+v, want %+v", patches, expected)
+	}
+}
+
+func TestSCIMGroupBuilderGrant_ThenRevoke(t *testing.T) {
+	const userID = "01JPWQP39ZE3X1NRHC3PJAWZVQ"
+
+	// The group holds whoever the PATCH requests added, so a cached read of it would be stale.
+	group := client.SCIMGroup{ID: onCallSCIMGroupID, DisplayName: "On-call"}
+	getGroup := func(*http.Request, []byte) (int, string) {
+		res, err := json.Marshal(group)
+		if err != nil {
+			t.Fatalf("Error encoding group: %s", err)
+		}
+
+		return http.StatusOK, string(res)
+	}
+	patchGroup := func(_ *http.Request, body []byte) (int, string) {
+		var patch client.SCIMPatchRequest
+		if err := json.Unmarshal(body, &patch); err != nil {
+			t.Fatalf("Error decoding patch body: %s", err)
+		}
+
+		if patch.Operations[0].Op == "add" {
+			group.Members = []client.SCIMMember{{Value: userID}}
+		} else {
+			group.Members = nil
+		}
+
+		return http.StatusNoContent, ""
+	}
+
+	var requests []test.Request
+	c := test.NewRoutingTestClient(nil,
+		test.WithHandler("GET /scim/v2/Groups/"+onCallSCIMGroupID, getGroup),
+		test.WithHandler("PATCH /scim/v2/Groups/"+onCallSCIMGroupID, patchGroup),
+		test.WithRecorder(&requests),
+		test.WithClientOptions(client.WithSCIMToken("scim")),
+	)
+	g := NewSCIMGroupBuilder(c)
+
+	if _, err := g.Grant(context.Background(), userPrincipal(userID), scimGroupEntitlement(onCallSCIMGroupID)); err != nil {
+		t.Fatalf("Expected no error granting, got %v", err)
+	}
+
+	annos, err := g.Revoke(context.Background(), &v2.Grant{Entitlement: scimGroupEntitlement(onCallSCIMGroupID), Principal: userPrincipal(userID)})
+	if err != nil {
+		t.Fatalf("Expected no error revoking, got %v", err)
+	}
+
+	if annos.Contains(&v2.GrantAlreadyRevoked{}) {
+		t.Error("Expected the membership added by the grant to be seen and removed")
+	}
+
+	if patches := scimGroupPatches(t, requests); len(patches) != 2 {
+		t.Errorf("Expected an add and a remove, got %+v", patches)
+	}
+}
+
+func TestSCIMGroupBuilderRevoke(t *testing.T) {
+	var requests []test.Request
+	g := NewSCIMGroupBuilder(newSCIMGroupTestClient(&requests))
+
+	membership := func(userID string) *v2.Grant {
+		return &v2.Grant{
+			Entitlement: scimGroupEntitlement(onCallSCIMGroupID),
+			Principal:   userPrincipal(userID),
+		}
+	}
+
+	annos, err := g.Revoke(context.Background(), membership("01JPWQP39ZE3X1NRHC3PJAWZVQ"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !annos.Contains(&v2.GrantAlreadyRevoked{}) {
+		t.Error("Expected GrantAlreadyRevoked annotation")
+	}
+
+	_, err = g.Revoke(context.Background(), membership("01JPWQNM50YGKQYFJYW61BBPD7"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []client.SCIMPatchRequest{
+		{
+			Schemas: []string{client.SCIMPatchOpSchema},
+			Operations: []client.SCIMPatchOperation{
+				{Op: "remove", Path: `members[value eq "01JPWQNM50YGKQYFJYW61BBPD7"]`},
+			},
+		},
+	}
+
+	if patches := scimGroupPatches(t, requests); !reflect.DeepEqual(patches, expected) {
+		t.Errorf("Unexpected patches: got %+v, want %+v", patches, expected)
+	}
+}
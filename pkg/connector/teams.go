@@ -0,0 +1,209 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const teamMemberEntitlement = "member"
+
+// teamBuilder syncs teams, which incident.io stores as entries of a catalog type
+// whose members are referenced by a user attribute.
+type teamBuilder struct {
+	resourceType     *v2.ResourceType
+	client           *client.APIClient
+	catalogTypes     *catalogTypes
+	catalogType      string
+	membersAttribute string
+}
+
+// ResourceType returns the resource type associated with teams.
+func (o *teamBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return teamResourceType
+}
+
+// List retrieves the entries of the team catalog type as team resources. The first page reads the
+// catalog types afresh and reports a missing catalog type or members attribute.
+func (o *teamBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, pToken *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	firstPage := pToken.Token == ""
+	if firstPage {
+		o.catalogTypes.refresh(teamResourceType.Id)
+	}
+
+	catalogTypeID, _, err := o.resolve(ctx, firstPage)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if catalogTypeID == "" {
+		return nil, "", nil, nil
+	}
+
+	bag, pageToken, err := getToken(pToken, teamResourceType)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	entries, nextPageToken, annos, err := o.client.ListCatalogEntries(ctx, catalogTypeID, client.PageOptions{
+		After:    pageToken,
+		PageSize: pToken.Size,
+	})
+	if err != nil {
+		l.Error("Error fetching team catalog entries", zap.Error(err))
+		return nil, "", nil, fmt.Errorf("error fetching team catalog entries: %w", err)
+	}
+
+	var resources []*v2.Resource
+	for _, entry := range entries {
+		profile := map[string]interface{}{
+			"catalog_entry_id": entry.ID,
+			"external_id":      entry.ExternalID,
+		}
+
+		teamResource, err := resource.NewGroupResource(
+			entry.Name,
+			teamResourceType,
+			entry.ID,
+			[]resource.GroupTraitOption{resource.WithGroupProfile(profile)},
+			resource.WithParentResourceID(parentResourceID),
+		)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("error creating team resource: %w", err)
+		}
+
+		resources = append(resources, teamResource)
+	}
+
+	err = bag.Next(nextPageToken)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	nextPageToken, err = bag.Marshal()
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return resources, nextPageToken, annos, nil
+}
+
+// Entitlements returns the member entitlement of a team.
+func (o *teamBuilder) Entitlements(_ context.Context, teamResource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			teamResource,
+			teamMemberEntitlement,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("%s Team Member", teamResource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Member of the %s team", teamResource.DisplayName)),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns a member grant for every user referenced by the team's members attribute.
+func (o *teamBuilder) Grants(ctx context.Context, teamResource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	_, membersAttributeID, err := o.resolve(ctx, false)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	if membersAttributeID == "" {
+		return nil, "", nil, nil
+	}
+
+	entry, annos, err := o.client.GetCatalogEntry(ctx, teamResource.Id.Resource)
+	if err != nil {
+		l.Error("Error fetching team catalog entry", zap.Error(err), zap.String("catalog_entry_id", teamResource.Id.Resource))
+		return nil, "", nil, fmt.Errorf("error fetching team catalog entry %s: %w", teamResource.Id.Resource, err)
+	}
+
+	var grants []*v2.Grant
+	seenUsers := make(map[string]bool)
+	for _, userID := range entry.AttributeValues[membersAttributeID].Literals() {
+		if seenUsers[userID] {
+			continue
+		}
+
+		seenUsers[userID] = true
+
+		principalID, err := resource.NewResourceID(userResourceType, userID)
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to create resource ID for user: %s", userID)
+		}
+
+		grants = append(grants, grant.NewGrant(teamResource, teamMemberEntitlement, principalID))
+	}
+
+	return grants, "", annos, nil
+}
+
+// resolve returns the IDs of the configured team catalog type and members attribute, looked up in
+// the catalog types read for the sync. Empty IDs are returned when the tenant has no matching catalog
+// type, or no matching attribute of type User, which is logged when warn is set.
+func (o *teamBuilder) resolve(ctx context.Context, warn bool) (string, string, error) {
+	l := ctxzap.Extract(ctx)
+
+	catalogType, ok, err := o.catalogTypes.get(ctx, o.catalogType)
+	if err != nil {
+		return "", "", err
+	}
+
+	if !ok {
+		if warn {
+			l.Warn("Team catalog type not found, skipping teams", zap.String("catalog_type", o.catalogType))
+		}
+		return "", "", nil
+	}
+
+	attribute, ok := findCatalogAttribute(catalogType, o.membersAttribute)
+	if !ok {
+		if warn {
+			l.Warn(
+				"Team members attribute not found, skipping team memberships",
+				zap.String("catalog_type", o.catalogType),
+				zap.String("attribute", o.membersAttribute),
+			)
+		}
+		return catalogType.ID, "", nil
+	}
+
+	// Other attribute types hold catalog entry or free text values that would be taken for user IDs.
+	if attribute.Type != catalogAttributeTypeUser {
+		if warn {
+			l.Warn(
+				"Team members attribute does not reference users, skipping team memberships",
+				zap.String("catalog_type", o.catalogType),
+				zap.String("attribute", o.membersAttribute),
+				zap.String("attribute_type", attribute.Type),
+			)
+		}
+		return catalogType.ID, "", nil
+	}
+
+	return catalogType.ID, attribute.ID, nil
+}
+
+// NewTeamBuilder initializes a new team builder reading teams from the given catalog type and members attribute.
+func NewTeamBuilder(c *client.APIClient, types *catalogTypes, catalogType, membersAttribute string) *teamBuilder {
+	return &teamBuilder{
+		resourceType:     teamResourceType,
+		client:           c,
+		catalogTypes:     types,
+		catalogType:      catalogType,
+		membersAttribute: membersAttribute,
+	}
+}
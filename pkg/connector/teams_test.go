@@ -0,0 +1,135 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+)
+
+const paymentsTeamID = "01HZ3TB1XK8N4C2V6E9J5PAYTM"
+
+var catalogRoutes = map[string]string{
+	"/v2/catalog_types":                     "catalogTypesMock.json",
+	"/v2/catalog_entries":                   "catalogEntriesTeamMock.json",
+	"/v2/catalog_entries/" + paymentsTeamID: "catalogEntryTeamMock.json",
+}
+
+// newTestTeamBuilder creates a team builder serving catalogRoutes, with its own catalog types.
+func newTestTeamBuilder(catalogType, membersAttribute string) *teamBuilder {
+	c := test.NewRoutingTestClient(catalogRoutes)
+	return NewTeamBuilder(c, newCatalogTypes(c), catalogType, membersAttribute)
+}
+
+func TestTeamBuilderList(t *testing.T) {
+	b := newTestTeamBuilder("Team", defaultTeamMembersAttribute)
+
+	resources, _, _, err := b.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"Payments", "Platform"}
+	if len(resources) != len(expected) {
+		t.Fatalf("Expected %d teams, got %d", len(expected), len(resources))
+	}
+
+	for i, res := range resources {
+		if res.DisplayName != expected[i] || res.Id.ResourceType != teamResourceType.Id {
+			t.Errorf("Expected team %s, got %s (%s)", expected[i], res.DisplayName, res.Id.ResourceType)
+		}
+	}
+}
+
+func TestTeamBuilderList_UnknownCatalogType(t *testing.T) {
+	b := newTestTeamBuilder("Squad", defaultTeamMembersAttribute)
+
+	resources, _, _, err := b.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resources) != 0 {
+		t.Errorf("Expected no teams, got %d", len(resources))
+	}
+}
+
+func TestTeamBuilderList_NextSyncReadsCatalogTypes(t *testing.T) {
+	b := newTestTeamBuilder("Team", defaultTeamMembersAttribute)
+
+	if _, _, _, err := b.List(context.Background(), nil, &pagination.Token{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The catalog types as read before the team catalog type was created.
+	b.catalogTypes.types = []client.CatalogType{}
+
+	resources, _, _, err := b.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resources) != 2 {
+		t.Errorf("Expected the next sync to find the team catalog type, got %d teams", len(resources))
+	}
+}
+
+func TestTeamBuilderGrants(t *testing.T) {
+	testCases := []struct {
+		name             string
+		membersAttribute string
+		expected         []string
+	}{
+		{
+			name:             "attribute by name",
+			membersAttribute: "members",
+			expected:         []string{"01JPWQNM50YGKQYFJYW61BBPD7", "01JPWQP39ZE3X1NRHC3PJAWZVQ"},
+		},
+		{
+			name:             "attribute by ID",
+			membersAttribute: "01HZ3T8GQ4M2B5Y6R7K9W0MEMB",
+			expected:         []string{"01JPWQNM50YGKQYFJYW61BBPD7", "01JPWQP39ZE3X1NRHC3PJAWZVQ"},
+		},
+		{
+			name:             "unknown attribute",
+			membersAttribute: "Engineers",
+		},
+		{
+			name:             "attribute not of type User",
+			membersAttribute: "Slack channel",
+		},
+	}
+
+	teamResource := &v2.Resource{
+		Id:          &v2.ResourceId{ResourceType: teamResourceType.Id, Resource: paymentsTeamID},
+		DisplayName: "Payments",
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newTestTeamBuilder("Team", tc.membersAttribute)
+
+			grants, _, _, err := b.Grants(context.Background(), teamResource, &pagination.Token{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(grants) != len(tc.expected) {
+				t.Fatalf("Expected %d grants, got %d", len(tc.expected), len(grants))
+			}
+
+			for i, g := range grants {
+				if g.Principal.Id.Resource != tc.expected[i] {
+					t.Errorf("Expected grant to %s, got %s", tc.expected[i], g.Principal.Id.Resource)
+				}
+
+				if g.Entitlement.Id != "team:"+paymentsTeamID+":"+teamMemberEntitlement {
+					t.Errorf("Unexpected entitlement %s", g.Entitlement.Id)
+				}
+			}
+		})
+	}
+}
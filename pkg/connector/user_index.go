@@ -0,0 +1,129 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+// userIndex reads every user once per sync and indexes the roles they hold. incident.io has no endpoint
+// listing roles or their holders, so the role and workflow builders share one index instead of each
+// paging through all users. Each of them calls refresh when it starts listing, and the users are read
+// again once a builder starts listing a second time, so every sync reads them afresh whichever builder
+// lists first. Account creation uses the users as read by the latest sync.
+type userIndex struct {
+	client *client.APIClient
+
+	mu      sync.Mutex
+	loaded  bool
+	userIDs map[string]bool
+	roles   []userRole
+	holders map[string][]string
+
+	// readers are the builders that started listing since the indexed users were dropped.
+	readers syncReaders
+}
+
+// newUserIndex creates an empty index, filled on first use.
+func newUserIndex(c *client.APIClient) *userIndex {
+	return &userIndex{client: c}
+}
+
+// refresh is called by a builder when it starts listing for a sync. If the builder already started
+// listing since the indexed users were dropped, a new sync has begun and they are dropped again so
+// that the next read lists them.
+func (x *userIndex) refresh(reader string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.readers.start(reader) {
+		x.loaded = false
+		x.userIDs = nil
+		x.roles = nil
+		x.holders = nil
+	}
+}
+
+// Roles returns the distinct roles held by users, in the order they are first seen.
+func (x *userIndex) Roles(ctx context.Context) ([]userRole, error) {
+	if err := x.load(ctx); err != nil {
+		return nil, err
+	}
+
+	return x.roles, nil
+}
+
+// Holders returns the IDs of the users holding a role as their base role or a custom role.
+func (x *userIndex) Holders(ctx context.Context, roleID string) ([]string, error) {
+	if err := x.load(ctx); err != nil {
+		return nil, err
+	}
+
+	return x.holders[roleID], nil
+}
+
+// IsUser reports whether id is the ID of a user.
+func (x *userIndex) IsUser(ctx context.Context, id string) (bool, error) {
+	if err := x.load(ctx); err != nil {
+		return false, err
+	}
+
+	return x.userIDs[id], nil
+}
+
+// load reads every page of users unless they have already been read.
+func (x *userIndex) load(ctx context.Context) error {
+	l := ctxzap.Extract(ctx)
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.loaded {
+		return nil
+	}
+
+	userIDs := make(map[string]bool)
+	holders := make(map[string][]string)
+	var roles []userRole
+
+	pageToken := ""
+	for {
+		users, nextPageToken, _, err := x.client.ListUsers(ctx, client.PageOptions{
+			After:    pageToken,
+			PageSize: client.ItemsPerPage,
+		})
+		if err != nil {
+			l.Error("Error fetching users", zap.Error(err))
+			return fmt.Errorf("error fetching users: %w", err)
+		}
+
+		for _, user := range users {
+			userIDs[user.ID] = true
+
+			for _, role := range userRoles(user) {
+				if _, ok := holders[role.ID]; !ok {
+					roles = append(roles, role)
+				}
+
+				holders[role.ID] = append(holders[role.ID], user.ID)
+			}
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+
+		pageToken = nextPageToken
+	}
+
+	x.userIDs = userIDs
+	x.roles = roles
+	x.holders = holders
+	x.loaded = true
+
+	return nil
+}
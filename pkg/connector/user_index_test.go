@@ -0,0 +1,39 @@
+package connector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/conductorone/baton-incident-io/pkg/test"
+)
+
+func TestUserIndexRefresh(t *testing.T) {
+	c := test.NewRoutingTestClient(map[string]string{"/v2/users": "usersMock.json"})
+	x := newUserIndex(c)
+
+	load := func() {
+		if _, err := x.Roles(context.Background()); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	// A sync lists roles, then workflows: both read the same users.
+	x.refresh(roleResourceType.Id)
+	load()
+	x.refresh(workflowResourceType.Id)
+	if !x.loaded {
+		t.Fatal("Expected the users to be kept within a sync")
+	}
+
+	// The next sync lists workflows first: the users are read again, and kept for the roles.
+	x.refresh(workflowResourceType.Id)
+	if x.loaded {
+		t.Fatal("Expected the users to be dropped when a builder starts a new sync")
+	}
+
+	load()
+	x.refresh(roleResourceType.Id)
+	if !x.loaded {
+		t.Error("Expected the users read for the workflows to be kept for the roles")
+	}
+}
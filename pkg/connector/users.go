@@ -3,10 +3,13 @@ package connector
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/conductorone/baton-incident-io/pkg/client"
 	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
 	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/connectorbuilder"
 	"github.com/conductorone/baton-sdk/pkg/pagination"
 	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
@@ -17,6 +20,7 @@ import (
 type UserBuilder struct {
 	resourceType *v2.ResourceType
 	client       *client.APIClient
+	users        *userIndex
 }
 
 // ResourceType returns the type of resource managed by this builder.
@@ -33,7 +37,7 @@ func (o *UserBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 		return nil, "", nil, err
 	}
 
-	users, nextPageToken, _, err := o.client.ListUsers(ctx, client.PageOptions{
+	users, nextPageToken, annos, err := o.client.ListUsers(ctx, client.PageOptions{
 		After:    pageToken,
 		PageSize: pToken.Size,
 	})
@@ -44,26 +48,9 @@ func (o *UserBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 
 	var resources []*v2.Resource
 	for _, user := range users {
-		profile := map[string]interface{}{
-			"user_id": user.ID,
-			"email":   user.Email,
-		}
-
-		userTraits := []resource.UserTraitOption{
-			resource.WithUserProfile(profile),
-			resource.WithEmail(user.Email, true),
-		}
-
-		// Create a Baton user resource
-		userResource, err := resource.NewUserResource(
-			user.Name,
-			userResourceType,
-			user.ID,
-			userTraits,
-			resource.WithParentResourceID(parentResourceID),
-		)
+		userResource, err := newUserResource(user, parentResourceID)
 		if err != nil {
-			return nil, "", nil, fmt.Errorf("error creating user resource: %w", err)
+			return nil, "", nil, err
 		}
 
 		resources = append(resources, userResource)
@@ -79,7 +66,7 @@ func (o *UserBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId,
 		return nil, "", nil, err
 	}
 
-	return resources, nextPageToken, nil, nil
+	return resources, nextPageToken, annos, nil
 }
 
 // Entitlements always returns an empty slice for users.
@@ -91,9 +78,230 @@ func (o *UserBuilder) Entitlements(_ context.Context, resource *v2.Resource, _ *
 func (o *UserBuilder) Grants(ctx context.Context, resource *v2.Resource, pToken *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
 	return nil, "", nil, nil
 }
-func NewUserBuilder(c *client.APIClient) *UserBuilder {
+
+// CreateAccountCapabilityDetails reports that accounts are created without a password: new users
+// sign in to incident.io through Slack or SSO.
+func (o *UserBuilder) CreateAccountCapabilityDetails(_ context.Context) (*v2.CredentialDetailsAccountProvisioning, annotations.Annotations, error) {
+	return &v2.CredentialDetailsAccountProvisioning{
+		SupportedCredentialOptions: []v2.CapabilityDetailCredentialOption{
+			v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+		},
+		PreferredCredentialOption: v2.CapabilityDetailCredentialOption_CAPABILITY_DETAIL_CREDENTIAL_OPTION_NO_PASSWORD,
+	}, nil, nil
+}
+
+// CreateAccount creates a user through the SCIM API and, when the account profile names a
+// base_role (by ID, slug or name), assigns it to the new user. Base roles are looked up among the
+// roles users hold; any other value is passed on as the ID of a base role no user holds, for the role
+// update to check. The user is removed again if the base role cannot be assigned, so that a retry
+// starts from scratch.
+func (o *UserBuilder) CreateAccount(
+	ctx context.Context,
+	accountInfo *v2.AccountInfo,
+	_ *v2.CredentialOptions,
+) (connectorbuilder.CreateAccountResponse, []*v2.PlaintextData, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	email := accountEmail(accountInfo)
+	if email == "" {
+		return nil, nil, nil, fmt.Errorf("incident.io: an email address is required to create an account")
+	}
+
+	profile := accountInfo.GetProfile().AsMap()
+
+	var baseRole client.Role
+	if baseRoleName, _ := profile["base_role"].(string); baseRoleName != "" {
+		roles, err := o.users.Roles(ctx)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		role, ok := findBaseRole(roles, baseRoleName)
+		if !ok {
+			role = client.Role{ID: baseRoleName, Name: baseRoleName}
+		}
+
+		baseRole = role
+	}
+
+	scimUser, annos, err := o.client.CreateSCIMUser(ctx, newSCIMUser(email, profile))
+	if err != nil {
+		l.Error("Error creating user", zap.String("email", email), zap.Error(err))
+		return nil, nil, annos, fmt.Errorf("error creating user %s: %w", email, err)
+	}
+
+	var user *client.User
+	if baseRole.ID != "" {
+		user, annos, err = o.client.UpdateUserRoles(ctx, scimUser.ID, baseRole.ID, nil)
+		if err != nil {
+			l.Error("Error assigning base role, removing the created user", zap.String("user_id", scimUser.ID), zap.Error(err))
+
+			if _, deleteErr := o.client.DeleteSCIMUser(ctx, scimUser.ID); deleteErr != nil {
+				l.Error("Error removing the created user", zap.String("user_id", scimUser.ID), zap.Error(deleteErr))
+				return nil, nil, annos, fmt.Errorf("error assigning base role %s to user %s, which could not be removed (%s): %w", baseRole.Name, scimUser.ID, deleteErr, err)
+			}
+
+			return nil, nil, annos, fmt.Errorf("error assigning base role %s to user %s, the user was removed: %w", baseRole.Name, scimUser.ID, err)
+		}
+	} else {
+		user, annos, err = o.client.GetUser(ctx, scimUser.ID)
+		if err != nil {
+			l.Error("Error fetching user", zap.String("user_id", scimUser.ID), zap.Error(err))
+			return nil, nil, annos, fmt.Errorf("error fetching user %s: %w", scimUser.ID, err)
+		}
+	}
+
+	userResource, err := newUserResource(*user, nil)
+	if err != nil {
+		return nil, nil, annos, err
+	}
+
+	return &v2.CreateAccountResponse_SuccessResult{
+		Resource:              userResource,
+		IsCreateAccountResult: true,
+	}, nil, annos, nil
+}
+
+// Delete removes a user from the organisation through the SCIM API, which deactivates it in incident.io.
+func (o *UserBuilder) Delete(ctx context.Context, resourceID *v2.ResourceId) (annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	if resourceID.ResourceType != userResourceType.Id {
+		return nil, fmt.Errorf("incident.io: cannot delete resource of type %s", resourceID.ResourceType)
+	}
+
+	annos, err := o.client.DeleteSCIMUser(ctx, resourceID.Resource)
+	if err != nil {
+		l.Error("Error deleting user", zap.String("user_id", resourceID.Resource), zap.Error(err))
+		return annos, fmt.Errorf("error deleting user %s: %w", resourceID.Resource, err)
+	}
+
+	return annos, nil
+}
+
+// accountEmail returns the primary email of an account, falling back to the first email and then the login.
+func accountEmail(accountInfo *v2.AccountInfo) string {
+	for _, email := range accountInfo.GetEmails() {
+		if email.GetIsPrimary() && email.GetAddress() != "" {
+			return email.GetAddress()
+		}
+	}
+
+	for _, email := range accountInfo.GetEmails() {
+		if email.GetAddress() != "" {
+			return email.GetAddress()
+		}
+	}
+
+	return accountInfo.GetLogin()
+}
+
+// newSCIMUser builds the SCIM user created for an account, named from the name, given_name and
+// family_name profile fields, or after the email address when none are set.
+func newSCIMUser(email string, profile map[string]interface{}) client.SCIMUser {
+	givenName, _ := profile["given_name"].(string)
+	familyName, _ := profile["family_name"].(string)
+
+	displayName, _ := profile["name"].(string)
+	if displayName == "" {
+		displayName = strings.TrimSpace(givenName + " " + familyName)
+	}
+	if displayName == "" {
+		displayName = email
+	}
+
+	return client.SCIMUser{
+		Schemas:     []string{client.SCIMUserSchema},
+		UserName:    email,
+		DisplayName: displayName,
+		Name: &client.SCIMName{
+			Formatted:  displayName,
+			GivenName:  givenName,
+			FamilyName: familyName,
+		},
+		Emails: []client.SCIMEmail{
+			{Value: email, Type: "work", Primary: true},
+		},
+		Active: true,
+	}
+}
+
+// newUserResource converts an incident.io user into a Baton user resource. The Slack user ID,
+// when the user has one, is added as an alternative login.
+func newUserResource(user client.User, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	profile := map[string]interface{}{
+		"user_id":       user.ID,
+		"email":         user.Email,
+		"slack_user_id": user.SlackUserID,
+		"role":          user.Role,
+		"base_role":     user.BaseRole.Name,
+		"status":        user.Status,
+	}
+
+	var customRoles []string
+	for _, customRole := range user.CustomRoles {
+		customRoles = append(customRoles, customRole.Name)
+	}
+	if len(customRoles) > 0 {
+		profile["custom_roles"] = strings.Join(customRoles, ", ")
+	}
+
+	if user.CreatedAt != nil {
+		profile["created_at"] = user.CreatedAt.Format(time.RFC3339)
+	}
+
+	if user.UpdatedAt != nil {
+		profile["updated_at"] = user.UpdatedAt.Format(time.RFC3339)
+	}
+
+	if user.DeactivatedAt != nil {
+		profile["deactivated_at"] = user.DeactivatedAt.Format(time.RFC3339)
+	}
+
+	var aliases []string
+	if user.SlackUserID != "" {
+		aliases = append(aliases, user.SlackUserID)
+	}
+
+	userTraits := []resource.UserTraitOption{
+		resource.WithUserProfile(profile),
+		resource.WithEmail(user.Email, true),
+		resource.WithUserLogin(user.Email, aliases...),
+		resource.WithStatus(userStatus(user)),
+	}
+
+	if user.CreatedAt != nil {
+		userTraits = append(userTraits, resource.WithCreatedAt(*user.CreatedAt))
+	}
+
+	// Create a Baton user resource
+	userResource, err := resource.NewUserResource(
+		user.Name,
+		userResourceType,
+		user.ID,
+		userTraits,
+		resource.WithParentResourceID(parentResourceID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating user resource: %w", err)
+	}
+
+	return userResource, nil
+}
+
+// userStatus maps the incident.io status of a user to the Baton user status.
+func userStatus(user client.User) v2.UserTrait_Status_Status {
+	if user.Deactivated() {
+		return v2.UserTrait_Status_STATUS_DISABLED
+	}
+
+	return v2.UserTrait_Status_STATUS_ENABLED
+}
+
+func NewUserBuilder(c *client.APIClient, users *userIndex) *UserBuilder {
 	return &UserBuilder{
 		resourceType: userResourceType,
 		client:       c,
+		users:        users,
 	}
 }
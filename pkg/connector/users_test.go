@@ -2,15 +2,23 @@ package connector
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"reflect"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/conductorone/baton-incident-io/pkg/client"
 	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 var pageOptions = client.PageOptions{
@@ -52,9 +60,17 @@ func TestIncidentClient_GetUsers(t *testing.T) {
 
 	for index, user := range result {
 		expectedUser := client.User{
-			ID:    test.Users[index]["id"].(string),
-			Name:  test.Users[index]["name"].(string),
-			Email: test.Users[index]["email"].(string),
+			ID:            test.Users[index]["id"].(string),
+			Name:          test.Users[index]["name"].(string),
+			Email:         test.Users[index]["email"].(string),
+			SlackUserID:   test.Users[index]["slack_user_id"].(string),
+			Role:          test.Users[index]["role"].(string),
+			BaseRole:      test.Users[index]["base_role"].(client.Role),
+			CustomRoles:   test.Users[index]["custom_roles"].([]client.Role),
+			CreatedAt:     test.Users[index]["created_at"].(*time.Time),
+			UpdatedAt:     test.Users[index]["updated_at"].(*time.Time),
+			Status:        test.Users[index]["status"].(string),
+			DeactivatedAt: test.Users[index]["deactivated_at"].(*time.Time),
 		}
 
 		if !reflect.DeepEqual(user, expectedUser) {
@@ -129,3 +145,470 @@ func TestIncidentClient_GetUsers_RequestDetails(t *testing.T) {
 		t.Fatal("Expected non-nil nextOptions")
 	}
 }
+
+func TestIncidentClient_GetUsers_BaseURL(t *testing.T) {
+	var capturedRequest *http.Request
+
+	mockTransport := &test.MockRoundTripper{
+		RoundTripFunc: func(req *http.Request) (*http.Response, error) {
+			capturedRequest = req
+			return test.NewMockResponse(http.StatusOK, `{"users":[],"pagination_meta":{"page_size":10}}`), nil
+		},
+	}
+
+	httpClient := &http.Client{Transport: mockTransport}
+	testClient := client.NewClient("test", uhttp.NewBaseHttpClient(httpClient), client.WithBaseURL("http://localhost:8080/proxy"))
+
+	_, _, _, err := testClient.ListUsers(context.Background(), pageOptions)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedURL := "http://localhost:8080/proxy/v2/users"
+	if actualURL := capturedRequest.URL.String(); !strings.HasPrefix(actualURL, expectedURL) {
+		t.Errorf("Expected URL to start with %s, got %s", expectedURL, actualURL)
+	}
+}
+
+func TestUserBuilderList_RateLimitAnnotations(t *testing.T) {
+	requests := 0
+	httpClient := &http.Client{Transport: newRateLimitedTransport(0, &requests)}
+	u := newTestUserBuilder(client.NewClient("test", uhttp.NewBaseHttpClient(httpClient)))
+
+	_, _, annos, err := u.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !annos.Contains(&v2.RateLimitDescription{}) {
+		t.Error("Expected the rate limit annotation to be returned by the builder")
+	}
+}
+
+func TestUserBuilderList_Profile(t *testing.T) {
+	u := newTestUserBuilder(test.NewRoutingTestClient(map[string]string{"/v2/users": "usersMock.json"}))
+
+	resources, _, _, err := u.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(resources) != len(test.Users) {
+		t.Fatalf("Expected %d users, got %d", len(test.Users), len(resources))
+	}
+
+	for index, res := range resources {
+		userTrait, err := resource.GetUserTrait(res)
+		if err != nil {
+			t.Fatalf("Expected a user trait on %s, got %v", res.Id.Resource, err)
+		}
+
+		slackUserID := test.Users[index]["slack_user_id"].(string)
+		if userTrait.GetLogin() != test.Users[index]["email"] || !slices.Equal(userTrait.GetLoginAliases(), []string{slackUserID}) {
+			t.Errorf("Expected login %s with alias %s, got %s %v", test.Users[index]["email"], slackUserID, userTrait.GetLogin(), userTrait.GetLoginAliases())
+		}
+
+		profile := userTrait.GetProfile().AsMap()
+		if profile["slack_user_id"] != slackUserID || profile["role"] != test.Users[index]["role"] {
+			t.Errorf("Unexpected profile for %s: %v", res.Id.Resource, profile)
+		}
+	}
+
+	first, err := resource.GetUserTrait(resources[0])
+	if err != nil {
+		t.Fatalf("Expected a user trait, got %v", err)
+	}
+
+	profile := first.GetProfile().AsMap()
+	if profile["created_at"] != "2025-03-20T10:15:00Z" || profile["updated_at"] != "2025-03-22T08:00:00Z" {
+		t.Errorf("Expected created and updated timestamps in the profile, got %v", profile)
+	}
+
+	if first.GetCreatedAt().AsTime().Format(time.RFC3339) != "2025-03-20T10:15:00Z" {
+		t.Errorf("Expected the created at time on the user trait, got %v", first.GetCreatedAt())
+	}
+
+	if profile["custom_roles"] != nil {
+		t.Errorf("Expected no custom roles for %s, got %v", resources[0].Id.Resource, profile["custom_roles"])
+	}
+}
+
+func TestUserBuilderList_Status(t *testing.T) {
+	u := newTestUserBuilder(test.NewRoutingTestClient(map[string]string{"/v2/users": "usersMock.json"}))
+
+	resources, _, _, err := u.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := map[string]v2.UserTrait_Status_Status{
+		"active":      v2.UserTrait_Status_STATUS_ENABLED,
+		"deactivated": v2.UserTrait_Status_STATUS_DISABLED,
+	}
+
+	for index, res := range resources {
+		userTrait, err := resource.GetUserTrait(res)
+		if err != nil {
+			t.Fatalf("Expected a user trait on %s, got %v", res.Id.Resource, err)
+		}
+
+		want := expected[test.Users[index]["status"].(string)]
+		if userTrait.GetStatus().GetStatus() != want {
+			t.Errorf("Expected status %s for %s, got %s", want, res.Id.Resource, userTrait.GetStatus().GetStatus())
+		}
+	}
+}
+
+func TestUserStatus(t *testing.T) {
+	deactivatedAt := time.Date(2025, 3, 25, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		user     client.User
+		expected v2.UserTrait_Status_Status
+	}{
+		{"active", client.User{Status: "active"}, v2.UserTrait_Status_STATUS_ENABLED},
+		{"no status", client.User{}, v2.UserTrait_Status_STATUS_ENABLED},
+		{"deactivated", client.User{Status: "Deactivated"}, v2.UserTrait_Status_STATUS_DISABLED},
+		{"deactivation time only", client.User{DeactivatedAt: &deactivatedAt}, v2.UserTrait_Status_STATUS_DISABLED},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := userStatus(tc.user); got != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, got)
+			}
+		})
+	}
+}
+
+// newTestUserBuilder creates a user builder with its own user index.
+func newTestUserBuilder(c *client.APIClient) *UserBuilder {
+	return NewUserBuilder(c, newUserIndex(c))
+}
+
+const newAccountUserID = "01JQ3NEWH1REXAMPLE0000000"
+
+// newAccountTestClient answers SCIM user creation and deletion, and the user list, get and role
+// update calls made around them. Role updates are rejected unless the base role is one users hold
+// or unheldRoleID.
+func newAccountTestClient(t *testing.T, scimToken string, requests *[]test.Request) *client.APIClient {
+	newUser := `{"user":{"id":"` + newAccountUserID + `","name":"Jane Doe","email":"jane@example.com","base_role":{"id":"01JPWQNJKAC407555HM47MP2V4","name":"Standard","slug":"user"},"status":"active"}}`
+
+	createUser := func(_ *http.Request, body []byte) (int, string) {
+		var user client.SCIMUser
+		if err := json.Unmarshal(body, &user); err != nil {
+			t.Fatalf("Error decoding SCIM user: %s", err)
+		}
+
+		user.ID = newAccountUserID
+		res, err := json.Marshal(user)
+		if err != nil {
+			t.Fatalf("Error encoding SCIM user: %s", err)
+		}
+
+		return http.StatusCreated, string(res)
+	}
+
+	updateRoles := func(_ *http.Request, body []byte) (int, string) {
+		var update client.UpdateUserRolesRequest
+		if err := json.Unmarshal(body, &update); err != nil {
+			t.Fatalf("Error decoding role update: %s", err)
+		}
+
+		if !slices.Contains(assignableRoleIDs, update.BaseRoleID) {
+			return http.StatusUnprocessableEntity, `{"type":"validation_error","status":422,"errors":[{"code":"not_found","message":"base role not found"}]}`
+		}
+
+		return http.StatusOK, newUser
+	}
+
+	return test.NewRoutingTestClient(map[string]string{
+		"GET /v2/users": "usersMock.json",
+	},
+		test.WithHandler("POST /scim/v2/Users", createUser),
+		test.WithHandler("GET /v2/users/"+newAccountUserID, func(*http.Request, []byte) (int, string) {
+			return http.StatusOK, newUser
+		}),
+		test.WithHandler("PUT /v2/users/"+newAccountUserID, updateRoles),
+		test.WithStatus("DELETE /scim/v2/Users/01JPWQP39ZE3X1NRHC3PJAWZVQ", http.StatusNoContent),
+		test.WithStatus("DELETE /scim/v2/Users/"+newAccountUserID, http.StatusNoContent),
+		test.WithRecorder(requests),
+		test.WithClientOptions(client.WithSCIMToken(scimToken)),
+	)
+}
+
+const (
+	// unheldRoleID is the ID of a base role that no user holds.
+	unheldRoleID = "01JPWQNJKAUNHELDR0LE000000"
+
+	// unassignableRoleID is a role ID that no user holds and that role updates reject.
+	unassignableRoleID = "01JPWQNJKAUNKNOWN000000000"
+)
+
+// assignableRoleIDs are the base roles that role updates accept.
+var assignableRoleIDs = []string{"01JPWQNJKADS4VZ8PEYV0PAQPA", "01JPWQNJKAC407555HM47MP2V4", unheldRoleID}
+
+// requestLines describes each request as "METHOD path token".
+func requestLines(requests []test.Request) []string {
+	lines := make([]string, 0, len(requests))
+	for _, req := range requests {
+		lines = append(lines, req.Method+" "+req.Path+" "+req.Token)
+	}
+
+	return lines
+}
+
+// createdSCIMUsers decodes the SCIM users created among requests.
+func createdSCIMUsers(t *testing.T, requests []test.Request) []client.SCIMUser {
+	var users []client.SCIMUser
+	for _, req := range requests {
+		if req.Method != http.MethodPost || req.Path != "/scim/v2/Users" {
+			continue
+		}
+
+		var user client.SCIMUser
+		if err := json.Unmarshal(req.Body, &user); err != nil {
+			t.Fatalf("Error decoding SCIM user: %s", err)
+		}
+		users = append(users, user)
+	}
+
+	return users
+}
+
+func newAccountInfo(t *testing.T, email string, profile map[string]interface{}) *v2.AccountInfo {
+	profileStruct, err := structpb.NewStruct(profile)
+	if err != nil {
+		t.Fatalf("Error creating profile: %s", err)
+	}
+
+	return &v2.AccountInfo{
+		Emails:  []*v2.AccountInfo_Email{{Address: email, IsPrimary: true}},
+		Login:   email,
+		Profile: profileStruct,
+	}
+}
+
+func TestUserBuilderCreateAccount(t *testing.T) {
+	var requests []test.Request
+	u := newTestUserBuilder(newAccountTestClient(t, "scim", &requests))
+
+	accountInfo := newAccountInfo(t, "jane@example.com", map[string]interface{}{
+		"given_name":  "Jane",
+		"family_name": "Doe",
+		"base_role":   "Standard",
+	})
+
+	response, _, _, err := u.CreateAccount(context.Background(), accountInfo, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedRequests := []string{
+		"GET /v2/users test",
+		"POST /scim/v2/Users scim",
+		"PUT /v2/users/01JQ3NEWH1REXAMPLE0000000 test",
+	}
+	if !slices.Equal(requestLines(requests), expectedRequests) {
+		t.Errorf("Unexpected requests: got %v, want %v", requestLines(requests), expectedRequests)
+	}
+
+	created := createdSCIMUsers(t, requests)
+	if len(created) != 1 {
+		t.Fatalf("Expected one SCIM user to be created, got %d", len(created))
+	}
+
+	if created[0].UserName != "jane@example.com" || created[0].DisplayName != "Jane Doe" || !created[0].Active {
+		t.Errorf("Unexpected SCIM user: %+v", created[0])
+	}
+
+	success, ok := response.(*v2.CreateAccountResponse_SuccessResult)
+	if !ok {
+		t.Fatalf("Expected a success result, got %T", response)
+	}
+
+	if success.GetResource().GetId().GetResource() != "01JQ3NEWH1REXAMPLE0000000" {
+		t.Errorf("Unexpected created resource: %v", success.GetResource().GetId())
+	}
+
+	userTrait, err := resource.GetUserTrait(success.GetResource())
+	if err != nil {
+		t.Fatalf("Expected a user trait, got %v", err)
+	}
+
+	if userTrait.GetProfile().AsMap()["base_role"] != "Standard" {
+		t.Errorf("Expected the Standard base role, got %v", userTrait.GetProfile().AsMap()["base_role"])
+	}
+}
+
+func TestUserBuilderCreateAccount_WithoutBaseRole(t *testing.T) {
+	var requests []test.Request
+	u := newTestUserBuilder(newAccountTestClient(t, "scim", &requests))
+
+	_, _, _, err := u.CreateAccount(context.Background(), newAccountInfo(t, "jane@example.com", map[string]interface{}{}), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedRequests := []string{
+		"POST /scim/v2/Users scim",
+		"GET /v2/users/01JQ3NEWH1REXAMPLE0000000 test",
+	}
+	if !slices.Equal(requestLines(requests), expectedRequests) {
+		t.Errorf("Unexpected requests: got %v, want %v", requestLines(requests), expectedRequests)
+	}
+
+	if created := createdSCIMUsers(t, requests); len(created) != 1 || created[0].DisplayName != "jane@example.com" {
+		t.Errorf("Expected the email to be used as display name, got %+v", created)
+	}
+}
+
+func TestUserBuilderCreateAccount_UnknownBaseRole(t *testing.T) {
+	var requests []test.Request
+	u := newTestUserBuilder(newAccountTestClient(t, "scim", &requests))
+
+	accountInfo := newAccountInfo(t, "jane@example.com", map[string]interface{}{"base_role": "superuser"})
+
+	_, _, _, err := u.CreateAccount(context.Background(), accountInfo, nil)
+	if err == nil {
+		t.Fatal("Expected an error for an unknown base role")
+	}
+
+	expectedRequests := []string{
+		"GET /v2/users test",
+		"POST /scim/v2/Users scim",
+		"PUT /v2/users/" + newAccountUserID + " test",
+		"DELETE /scim/v2/Users/" + newAccountUserID + " scim",
+	}
+	if !slices.Equal(requestLines(requests), expectedRequests) {
+		t.Errorf("Expected the created user to be removed again: got %v, want %v", requestLines(requests), expectedRequests)
+	}
+
+	var update client.UpdateUserRolesRequest
+	for _, req := range requests {
+		if req.Method == http.MethodPut {
+			if err := json.Unmarshal(req.Body, &update); err != nil {
+				t.Fatalf("Error decoding role update: %s", err)
+			}
+		}
+	}
+
+	if update.BaseRoleID != "superuser" {
+		t.Errorf("Expected a value matching no held role to be tried as an ID, got %q", update.BaseRoleID)
+	}
+}
+
+func TestUserBuilderCreateAccount_BaseRoleBySlugAndID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		baseRole string
+	}{
+		{name: "ID", baseRole: "01JPWQNJKADS4VZ8PEYV0PAQPA"},
+		{name: "slug", baseRole: "owner"},
+		{name: "name", baseRole: "Owner"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var requests []test.Request
+			u := newTestUserBuilder(newAccountTestClient(t, "scim", &requests))
+
+			_, _, _, err := u.CreateAccount(context.Background(), newAccountInfo(t, "jane@example.com", map[string]interface{}{"base_role": tc.baseRole}), nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			var update client.UpdateUserRolesRequest
+			for _, req := range requests {
+				if req.Method == http.MethodPut {
+					if err := json.Unmarshal(req.Body, &update); err != nil {
+						t.Fatalf("Error decoding role update: %s", err)
+					}
+				}
+			}
+
+			if update.BaseRoleID != "01JPWQNJKADS4VZ8PEYV0PAQPA" {
+				t.Errorf("Expected the Owner base role to be assigned, got %q", update.BaseRoleID)
+			}
+		})
+	}
+}
+
+func TestUserBuilderCreateAccount_BaseRoleByID(t *testing.T) {
+	// No user holds this base role, so it can only be named by its ID.
+	var requests []test.Request
+	u := newTestUserBuilder(newAccountTestClient(t, "scim", &requests))
+
+	_, _, _, err := u.CreateAccount(context.Background(), newAccountInfo(t, "jane@example.com", map[string]interface{}{"base_role": unheldRoleID}), nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var update client.UpdateUserRolesRequest
+	for _, req := range requests {
+		if req.Method == http.MethodPut {
+			if err := json.Unmarshal(req.Body, &update); err != nil {
+				t.Fatalf("Error decoding role update: %s", err)
+			}
+		}
+	}
+
+	if update.BaseRoleID != unheldRoleID {
+		t.Errorf("Expected the base role ID to be passed to the role update, got %q", update.BaseRoleID)
+	}
+}
+
+func TestUserBuilderCreateAccount_RoleUpdateFails(t *testing.T) {
+	var requests []test.Request
+	u := newTestUserBuilder(newAccountTestClient(t, "scim", &requests))
+
+	_, _, _, err := u.CreateAccount(context.Background(), newAccountInfo(t, "jane@example.com", map[string]interface{}{"base_role": unassignableRoleID}), nil)
+	if err == nil {
+		t.Fatal("Expected an error when the base role cannot be assigned")
+	}
+
+	expectedRequests := []string{
+		"GET /v2/users test",
+		"POST /scim/v2/Users scim",
+		"PUT /v2/users/" + newAccountUserID + " test",
+		"DELETE /scim/v2/Users/" + newAccountUserID + " scim",
+	}
+	if !slices.Equal(requestLines(requests), expectedRequests) {
+		t.Errorf("Expected the created user to be removed again: got %v, want %v", requestLines(requests), expectedRequests)
+	}
+}
+
+func TestUserBuilderCreateAccount_MissingSCIMToken(t *testing.T) {
+	var requests []test.Request
+	u := newTestUserBuilder(newAccountTestClient(t, "", &requests))
+
+	_, _, _, err := u.CreateAccount(context.Background(), newAccountInfo(t, "jane@example.com", map[string]interface{}{}), nil)
+	if !errors.Is(err, client.ErrSCIMTokenMissing) {
+		t.Fatalf("Expected ErrSCIMTokenMissing, got %v", err)
+	}
+
+	if len(requests) != 0 {
+		t.Errorf("Expected no requests, got %v", requests)
+	}
+}
+
+func TestUserBuilderDelete(t *testing.T) {
+	var requests []test.Request
+	u := newTestUserBuilder(newAccountTestClient(t, "scim", &requests))
+
+	_, err := u.Delete(context.Background(), &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "01JPWQP39ZE3X1NRHC3PJAWZVQ"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expectedRequests := []string{"DELETE /scim/v2/Users/01JPWQP39ZE3X1NRHC3PJAWZVQ scim"}
+	if !slices.Equal(requestLines(requests), expectedRequests) {
+		t.Errorf("Unexpected requests: got %v, want %v", requestLines(requests), expectedRequests)
+	}
+
+	_, err = u.Delete(context.Background(), &v2.ResourceId{ResourceType: userResourceType.Id, Resource: "01JPWQNOTFOUND"})
+	if err == nil {
+		t.Fatal("Expected an error deleting an unknown user")
+	}
+}
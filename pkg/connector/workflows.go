@@ -0,0 +1,350 @@
+package connector
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/annotations"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/entitlement"
+	"github.com/conductorone/baton-sdk/pkg/types/grant"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/zap/ctxzap"
+	"go.uber.org/zap"
+)
+
+const workflowReferencedEntitlement = "referenced"
+
+// workflowReferences holds the users, schedules and escalation paths a workflow's steps refer to.
+type workflowReferences struct {
+	users           []string
+	schedules       []string
+	escalationPaths []string
+}
+
+// workflowBuilder syncs workflows along with the users, schedules and escalation paths their steps
+// refer to, such as the people a workflow pages. Step parameters are untyped, so referenced IDs are
+// recognised by matching them against the known users, schedules and escalation paths. incident.io
+// returns no owner or creator for a workflow, so none is synced.
+type workflowBuilder struct {
+	resourceType *v2.ResourceType
+	client       *client.APIClient
+	users        *userIndex
+
+	mu              sync.Mutex
+	referencedUsers map[string][]string
+}
+
+// ResourceType returns the resource type associated with workflows.
+func (o *workflowBuilder) ResourceType(ctx context.Context) *v2.ResourceType {
+	return workflowResourceType
+}
+
+// List retrieves every workflow. The endpoint is not paginated and leaves out the steps, so each
+// workflow is read once on its own and the users its steps refer to are kept for Grants.
+func (o *workflowBuilder) List(ctx context.Context, parentResourceID *v2.ResourceId, _ *pagination.Token) ([]*v2.Resource, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	// The endpoint is not paginated, so each sync lists workflows once: drop what the previous sync kept.
+	o.mu.Lock()
+	o.referencedUsers = nil
+	o.mu.Unlock()
+	o.users.refresh(workflowResourceType.Id)
+
+	summaries, annos, err := o.client.ListWorkflows(ctx)
+	if err != nil {
+		l.Error("Error fetching workflows", zap.Error(err))
+		return nil, "", nil, fmt.Errorf("error fetching workflows: %w", err)
+	}
+
+	workflows := make([]client.Workflow, 0, len(summaries))
+	for _, summary := range summaries {
+		workflow, _, err := o.client.GetWorkflow(ctx, summary.ID)
+		if err != nil {
+			l.Error("Error fetching workflow", zap.Error(err), zap.String("workflow_id", summary.ID))
+			return nil, "", nil, fmt.Errorf("error fetching workflow %s: %w", summary.ID, err)
+		}
+
+		workflows = append(workflows, *workflow)
+	}
+
+	knownKinds, err := o.resolve(ctx, workflows)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	resources := make([]*v2.Resource, 0, len(workflows))
+	for _, workflow := range workflows {
+		references := workflowReferencesOf(workflow, knownKinds)
+
+		workflowResource, err := newWorkflowResource(workflow, references, parentResourceID)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		o.keepReferencedUsers(workflow.ID, references.users)
+		resources = append(resources, workflowResource)
+	}
+
+	return resources, "", annos, nil
+}
+
+// Entitlements returns the entitlement held by users a workflow refers to.
+func (o *workflowBuilder) Entitlements(_ context.Context, workflowResource *v2.Resource, _ *pagination.Token) ([]*v2.Entitlement, string, annotations.Annotations, error) {
+	return []*v2.Entitlement{
+		entitlement.NewAssignmentEntitlement(
+			workflowResource,
+			workflowReferencedEntitlement,
+			entitlement.WithGrantableTo(userResourceType),
+			entitlement.WithDisplayName(fmt.Sprintf("Referenced by %s", workflowResource.DisplayName)),
+			entitlement.WithDescription(fmt.Sprintf("Referenced by the steps of the %s workflow, for example to be paged", workflowResource.DisplayName)),
+		),
+	}, "", nil, nil
+}
+
+// Grants returns a grant for every user referenced by the steps of a workflow. The users kept by List
+// are used when there are some, and the workflow is only read again otherwise.
+func (o *workflowBuilder) Grants(ctx context.Context, workflowResource *v2.Resource, _ *pagination.Token) ([]*v2.Grant, string, annotations.Annotations, error) {
+	l := ctxzap.Extract(ctx)
+
+	workflowID := workflowResource.Id.Resource
+	if userIDs, ok := o.takeReferencedUsers(workflowID); ok {
+		grants, err := workflowGrants(workflowResource, userIDs)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		return grants, "", nil, nil
+	}
+
+	workflow, annos, err := o.client.GetWorkflow(ctx, workflowID)
+	if err != nil {
+		l.Error("Error fetching workflow", zap.Error(err), zap.String("workflow_id", workflowID))
+		return nil, "", nil, fmt.Errorf("error fetching workflow %s: %w", workflowID, err)
+	}
+
+	var userIDs []string
+	for _, literal := range stepLiterals(*workflow) {
+		isUser, err := o.users.IsUser(ctx, literal)
+		if err != nil {
+			return nil, "", nil, err
+		}
+
+		if isUser {
+			userIDs = append(userIDs, literal)
+		}
+	}
+
+	grants, err := workflowGrants(workflowResource, userIDs)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	return grants, "", annos, nil
+}
+
+// keepReferencedUsers remembers the users a listed workflow refers to for Grants.
+func (o *workflowBuilder) keepReferencedUsers(workflowID string, userIDs []string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.referencedUsers == nil {
+		o.referencedUsers = make(map[string][]string)
+	}
+
+	o.referencedUsers[workflowID] = userIDs
+}
+
+// takeReferencedUsers returns the users remembered for a workflow and forgets them, as the grants of
+// each workflow are read once per sync.
+func (o *workflowBuilder) takeReferencedUsers(workflowID string) ([]string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	userIDs, ok := o.referencedUsers[workflowID]
+	delete(o.referencedUsers, workflowID)
+
+	return userIDs, ok
+}
+
+// resolve returns the resource type of every step literal of the workflows that is the ID of a user,
+// schedule or escalation path. Users are looked up in the user index shared with the role builder.
+// Schedules and escalation paths are only paged through while some literals are left unresolved.
+func (o *workflowBuilder) resolve(ctx context.Context, workflows []client.Workflow) (map[string]*v2.ResourceType, error) {
+	l := ctxzap.Extract(ctx)
+
+	knownKinds := make(map[string]*v2.ResourceType)
+	pending := make(map[string]bool)
+	for _, workflow := range workflows {
+		for _, literal := range stepLiterals(workflow) {
+			isUser, err := o.users.IsUser(ctx, literal)
+			if err != nil {
+				return nil, err
+			}
+
+			if isUser {
+				knownKinds[literal] = userResourceType
+			} else {
+				pending[literal] = true
+			}
+		}
+	}
+
+	pageToken := ""
+	for len(pending) > 0 {
+		schedules, nextPageToken, _, err := o.client.ListSchedules(ctx, client.PageOptions{After: pageToken, PageSize: client.ItemsPerPage})
+		if err != nil {
+			l.Error("Error fetching schedules", zap.Error(err))
+			return nil, fmt.Errorf("error fetching schedules: %w", err)
+		}
+
+		for _, schedule := range schedules {
+			if pending[schedule.ID] {
+				knownKinds[schedule.ID] = scheduleResourceType
+				delete(pending, schedule.ID)
+			}
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+
+		pageToken = nextPageToken
+	}
+
+	pageToken = ""
+	for len(pending) > 0 {
+		escalationPaths, nextPageToken, _, err := o.client.ListEscalationPaths(ctx, client.PageOptions{After: pageToken, PageSize: client.ItemsPerPage})
+		if err != nil {
+			l.Error("Error fetching escalation paths", zap.Error(err))
+			return nil, fmt.Errorf("error fetching escalation paths: %w", err)
+		}
+
+		for _, escalationPath := range escalationPaths {
+			if pending[escalationPath.ID] {
+				knownKinds[escalationPath.ID] = escalationPathResourceType
+				delete(pending, escalationPath.ID)
+			}
+		}
+
+		if nextPageToken == "" {
+			break
+		}
+
+		pageToken = nextPageToken
+	}
+
+	return knownKinds, nil
+}
+
+// stepLiterals returns the distinct literal values bound to the step parameters of a workflow, in
+// the order they first appear.
+func stepLiterals(workflow client.Workflow) []string {
+	var literals []string
+	seen := make(map[string]bool)
+	for _, step := range workflow.Steps {
+		for _, binding := range step.ParamBindings {
+			for _, literal := range binding.Literals() {
+				if seen[literal] {
+					continue
+				}
+
+				seen[literal] = true
+				literals = append(literals, literal)
+			}
+		}
+	}
+
+	return literals
+}
+
+// workflowReferencesOf returns the users, schedules and escalation paths bound to the step parameters
+// of a workflow, in the order they first appear.
+func workflowReferencesOf(workflow client.Workflow, knownKinds map[string]*v2.ResourceType) workflowReferences {
+	var references workflowReferences
+	for _, literal := range stepLiterals(workflow) {
+		switch knownKinds[literal] {
+		case userResourceType:
+			references.users = append(references.users, literal)
+		case scheduleResourceType:
+			references.schedules = append(references.schedules, literal)
+		case escalationPathResourceType:
+			references.escalationPaths = append(references.escalationPaths, literal)
+		}
+	}
+
+	return references
+}
+
+// workflowGrants returns the referenced entitlement grant of a workflow for each user.
+func workflowGrants(workflowResource *v2.Resource, userIDs []string) ([]*v2.Grant, error) {
+	grants := make([]*v2.Grant, 0, len(userIDs))
+	for _, userID := range userIDs {
+		principalID, err := resource.NewResourceID(userResourceType, userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create resource ID for user: %s", userID)
+		}
+
+		grants = append(grants, grant.NewGrant(workflowResource, workflowReferencedEntitlement, principalID))
+	}
+
+	return grants, nil
+}
+
+// newWorkflowResource creates a Baton resource from a workflow and what its steps refer to.
+func newWorkflowResource(workflow client.Workflow, references workflowReferences, parentResourceID *v2.ResourceId) (*v2.Resource, error) {
+	trigger := workflow.Trigger.Label
+	if trigger == "" {
+		trigger = workflow.Trigger.Name
+	}
+
+	profile := map[string]interface{}{
+		"workflow_id":       workflow.ID,
+		"folder":            workflow.Folder,
+		"trigger":           trigger,
+		"trigger_name":      workflow.Trigger.Name,
+		"state":             workflow.State,
+		"enabled":           workflow.State == client.WorkflowStateActive,
+		"runs_on_incidents": workflow.RunsOnIncidents,
+		"version":           workflow.Version,
+		"step_count":        len(workflow.Steps),
+	}
+
+	if len(references.users) > 0 {
+		profile["referenced_users"] = strings.Join(references.users, ", ")
+	}
+
+	if len(references.schedules) > 0 {
+		profile["referenced_schedules"] = strings.Join(references.schedules, ", ")
+	}
+
+	if len(references.escalationPaths) > 0 {
+		profile["referenced_escalation_paths"] = strings.Join(references.escalationPaths, ", ")
+	}
+
+	workflowResource, err := resource.NewGroupResource(
+		workflow.Name,
+		workflowResourceType,
+		workflow.ID,
+		[]resource.GroupTraitOption{resource.WithGroupProfile(profile)},
+		resource.WithParentResourceID(parentResourceID),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating workflow resource: %w", err)
+	}
+
+	return workflowResource, nil
+}
+
+// NewWorkflowBuilder initializes a new workflow builder.
+func NewWorkflowBuilder(c *client.APIClient, users *userIndex) *workflowBuilder {
+	return &workflowBuilder{
+		resourceType: workflowResourceType,
+		client:       c,
+		users:        users,
+	}
+}
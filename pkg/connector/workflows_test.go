@@ -0,0 +1,171 @@
+package connector
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/conductorone/baton-incident-io/pkg/client"
+	"github.com/conductorone/baton-incident-io/pkg/test"
+	v2 "github.com/conductorone/baton-sdk/pb/c1/connector/v2"
+	"github.com/conductorone/baton-sdk/pkg/pagination"
+	"github.com/conductorone/baton-sdk/pkg/types/resource"
+)
+
+const pagePaymentsWorkflowID = "01JS1WF0PAGEPAYMENTS000000"
+
+const draftDigestWorkflowID = "01JS1WF0DRAFTDIGEST0000000"
+
+func newWorkflowTestClient(opts ...test.RoutingOption) *client.APIClient {
+	return test.NewRoutingTestClient(map[string]string{
+		"/v2/workflows": "workflowsMock.json",
+		"/v2/workflows/" + pagePaymentsWorkflowID: "workflowPagePaymentsMock.json",
+		"/v2/workflows/" + draftDigestWorkflowID:  "workflowDraftDigestMock.json",
+		"/v2/users":                               "usersMock.json",
+		"/v2/schedules":                           "schedulesMock.json",
+		"/v2/escalation_paths":                    "escalationPathsMock.json",
+	}, opts...)
+}
+
+func TestWorkflowBuilderList(t *testing.T) {
+	var requests []test.Request
+	c := newWorkflowTestClient(test.WithRecorder(&requests))
+	w := NewWorkflowBuilder(c, newUserIndex(c))
+
+	resources, _, _, err := w.List(context.Background(), nil, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Each workflow is read once, and users, schedules and escalation paths are listed once for all of them.
+	expectedRequests := []string{
+		"GET /v2/workflows test",
+		"GET /v2/workflows/" + pagePaymentsWorkflowID + " test",
+		"GET /v2/workflows/" + draftDigestWorkflowID + " test",
+		"GET /v2/users test",
+		"GET /v2/schedules test",
+		"GET /v2/escalation_paths test",
+	}
+	if actual := requestLines(requests); !reflect.DeepEqual(actual, expectedRequests) {
+		t.Errorf("Unexpected requests: got %v, want %v", actual, expectedRequests)
+	}
+
+	if len(resources) != 2 {
+		t.Fatalf("Expected 2 workflows, got %d", len(resources))
+	}
+
+	groupTrait, err := resource.GetGroupTrait(resources[0])
+	if err != nil {
+		t.Fatalf("Expected a group trait, got %v", err)
+	}
+
+	expected := map[string]interface{}{
+		"workflow_id":                 pagePaymentsWorkflowID,
+		"folder":                      "Paging",
+		"trigger":                     "Incident updated",
+		"trigger_name":                "incident.updated",
+		"state":                       "active",
+		"enabled":                     true,
+		"runs_on_incidents":           "newly_created",
+		"version":                     float64(3),
+		"step_count":                  float64(2),
+		"referenced_users":            "01JPWQP39ZE3X1NRHC3PJAWZVQ, 01JPWQNM50YGKQYFJYW61BBPD7",
+		"referenced_schedules":        "01JQ77YN7BRVRA81T9STQ41HB2",
+		"referenced_escalation_paths": "01JR0Q3XAD9K5Y4W8N2M6P1EPA",
+	}
+
+	if profile := groupTrait.GetProfile().AsMap(); !reflect.DeepEqual(profile, expected) {
+		t.Errorf("Unexpected profile: got %v, want %v", profile, expected)
+	}
+
+	draftTrait, err := resource.GetGroupTrait(resources[1])
+	if err != nil {
+		t.Fatalf("Expected a group trait, got %v", err)
+	}
+
+	draft := draftTrait.GetProfile().AsMap()
+	if draft["enabled"] != false || draft["trigger"] != "scheduled" || draft["referenced_users"] != nil {
+		t.Errorf("Unexpected draft workflow profile: %v", draft)
+	}
+}
+
+func TestWorkflowBuilderGrants(t *testing.T) {
+	c := newWorkflowTestClient()
+	w := NewWorkflowBuilder(c, newUserIndex(c))
+
+	workflowResource := &v2.Resource{Id: &v2.ResourceId{ResourceType: workflowResourceType.Id, Resource: pagePaymentsWorkflowID}}
+
+	grants, _, _, err := w.Grants(context.Background(), workflowResource, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var actual []string
+	for _, g := range grants {
+		actual = append(actual, g.Principal.Id.Resource+":"+g.Entitlement.Id)
+	}
+
+	// Alejandro is referenced by both steps but holds a single grant; schedules and escalation
+	// paths are only reported in the profile.
+	expected := []string{
+		"01JPWQP39ZE3X1NRHC3PJAWZVQ:workflow:" + pagePaymentsWorkflowID + ":referenced",
+		"01JPWQNM50YGKQYFJYW61BBPD7:workflow:" + pagePaymentsWorkflowID + ":referenced",
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected grants: got %v, want %v", actual, expected)
+	}
+}
+
+func TestWorkflowBuilderGrants_AfterList(t *testing.T) {
+	c := newWorkflowTestClient()
+	w := NewWorkflowBuilder(c, newUserIndex(c))
+
+	if _, _, _, err := w.List(context.Background(), nil, &pagination.Token{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if _, ok := w.referencedUsers[pagePaymentsWorkflowID]; !ok {
+		t.Fatal("Expected List to keep the users the workflow refers to")
+	}
+
+	workflowResource := &v2.Resource{Id: &v2.ResourceId{ResourceType: workflowResourceType.Id, Resource: pagePaymentsWorkflowID}}
+
+	grants, _, _, err := w.Grants(context.Background(), workflowResource, &pagination.Token{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(grants) != 2 {
+		t.Errorf("Expected 2 grants, got %d", len(grants))
+	}
+
+	if _, ok := w.referencedUsers[pagePaymentsWorkflowID]; ok {
+		t.Error("Expected Grants to use up the users kept by List")
+	}
+}
+
+func TestWorkflowBuilderList_NothingReferenced(t *testing.T) {
+	var requests []test.Request
+	c := newWorkflowTestClient(
+		test.WithHandler("/v2/workflows", func(*http.Request, []byte) (int, string) {
+			return http.StatusOK, `{"workflows":[{"id":"` + draftDigestWorkflowID + `","name":"Weekly incident digest","state":"draft"}]}`
+		}),
+		test.WithRecorder(&requests),
+	)
+	w := NewWorkflowBuilder(c, newUserIndex(c))
+
+	if _, _, _, err := w.List(context.Background(), nil, &pagination.Token{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// A workflow whose steps bind no values needs no users, schedules or escalation paths listed.
+	expected := []string{
+		"GET /v2/workflows test",
+		"GET /v2/workflows/" + draftDigestWorkflowID + " test",
+	}
+	if actual := requestLines(requests); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Unexpected requests: got %v, want %v", actual, expected)
+	}
+}
@@ -1,8 +1,11 @@
 package test
 
 import (
+	"io"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/conductorone/baton-incident-io/pkg/client"
 	"github.com/conductorone/baton-sdk/pkg/uhttp"
@@ -11,18 +14,61 @@ import (
 var (
 	Users = []map[string]interface{}{
 		{
-			"id":    "01JPWQNM50YGKQYFJYW61BBPD7",
-			"name":  "test",
-			"email": "test@example.com",
+			"id":            "01JPWQNM50YGKQYFJYW61BBPD7",
+			"name":          "test",
+			"email":         "test@example.com",
+			"slack_user_id": "U081GLUN17W",
+			"role":          "owner",
+			"base_role": client.Role{
+				ID:          "01JPWQNJKADS4VZ8PEYV0PAQPA",
+				Name:        "Owner",
+				Description: "A base role managed by incident.io for owners of your account.",
+				Slug:        "owner",
+			},
+			"custom_roles":   []client.Role{},
+			"created_at":     timestamp("2025-03-20T10:15:00Z"),
+			"updated_at":     timestamp("2025-03-22T08:00:00Z"),
+			"status":         "active",
+			"deactivated_at": (*time.Time)(nil),
 		},
 		{
-			"id":    "01JPWQP39ZE3X1NRHC3PJAWZVQ",
-			"name":  "Alejandro",
-			"email": "alejandro@example.com",
+			"id":            "01JPWQP39ZE3X1NRHC3PJAWZVQ",
+			"name":          "Alejandro",
+			"email":         "alejandro@example.com",
+			"slack_user_id": "U083SJ36LCD",
+			"role":          "viewer",
+			"base_role": client.Role{
+				ID:          "01JPWQNJKAC407555HM47MP2V4",
+				Name:        "Standard",
+				Description: "A base role managed by incident.io for users within your account.",
+				Slug:        "user",
+			},
+			"custom_roles": []client.Role{
+				{
+					ID:          "01JPWR2D8K4Q7TNB3V6XHCM0SA",
+					Name:        "Workflow Editor",
+					Description: "Can create and edit workflows.",
+					Slug:        "workflow-editor",
+				},
+			},
+			"created_at":     (*time.Time)(nil),
+			"updated_at":     (*time.Time)(nil),
+			"status":         "deactivated",
+			"deactivated_at": timestamp("2025-03-25T12:00:00Z"),
 		},
 	}
 )
 
+// timestamp parses an RFC 3339 time used in the mock responses.
+func timestamp(value string) *time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+
+	return &t
+}
+
 // Custom RoundTripper for testing.
 type TestRoundTripper struct {
 	response *http.Response
@@ -59,6 +105,126 @@ func NewTestClient(response *http.Response, err error) *client.APIClient {
 	return newClientT
 }
 
+// Request is a request received by a routing test client.
+type Request struct {
+	Method string
+	Path   string
+	Query  string
+	// Token is the bearer token the request was authenticated with.
+	Token string
+	Body  []byte
+}
+
+// Handler answers a routed request with a status code and a body. body is the request body.
+type Handler func(req *http.Request, body []byte) (int, string)
+
+// RoutingOption configures a client created by NewRoutingTestClient.
+type RoutingOption func(r *router)
+
+type router struct {
+	files      map[string]string
+	handlers   map[string]Handler
+	requests   *[]Request
+	clientOpts []client.ClientOpt
+}
+
+// WithHandler answers requests matching route, a path or a "METHOD /path" pair, with handler.
+func WithHandler(route string, handler Handler) RoutingOption {
+	return func(r *router) {
+		r.handlers[route] = handler
+	}
+}
+
+// WithStatus answers requests matching route with an empty response of the given status code.
+func WithStatus(route string, statusCode int) RoutingOption {
+	return WithHandler(route, func(*http.Request, []byte) (int, string) {
+		return statusCode, ""
+	})
+}
+
+// WithRecorder appends every request the client sends to requests.
+func WithRecorder(requests *[]Request) RoutingOption {
+	return func(r *router) {
+		r.requests = requests
+	}
+}
+
+// WithClientOptions passes options to the API client, e.g. a SCIM token.
+func WithClientOptions(opts ...client.ClientOpt) RoutingOption {
+	return func(r *router) {
+		r.clientOpts = append(r.clientOpts, opts...)
+	}
+}
+
+// NewRoutingTestClient creates a test client that answers each request with the mock response
+// file registered for its "METHOD /path" pair or, failing that, for its path. Any other request
+// is answered with a 404.
+func NewRoutingTestClient(routes map[string]string, opts ...RoutingOption) *client.APIClient {
+	r := &router{
+		files:    routes,
+		handlers: map[string]Handler{},
+	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	httpClient := &http.Client{Transport: &MockRoundTripper{RoundTripFunc: r.roundTrip}}
+
+	return client.NewClient("test", uhttp.NewBaseHttpClient(httpClient), r.clientOpts...)
+}
+
+func (r *router) roundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if r.requests != nil {
+		*r.requests = append(*r.requests, Request{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  req.URL.RawQuery,
+			Token:  strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "),
+			Body:   body,
+		})
+	}
+
+	for _, route := range []string{req.Method + " " + req.URL.Path, req.URL.Path} {
+		if handler, ok := r.handlers[route]; ok {
+			statusCode, res := handler(req, body)
+			return NewMockResponse(statusCode, res), nil
+		}
+
+		if fileName, ok := r.files[route]; ok {
+			res, err := ReadFile(fileName)
+			if err != nil {
+				return nil, err
+			}
+
+			return NewMockResponse(http.StatusOK, res), nil
+		}
+	}
+
+	return NewMockResponse(http.StatusNotFound, `{"type":"not_found","status":404}`), nil
+}
+
+// NewMockResponse builds a JSON response with the given status code and body.
+func NewMockResponse(statusCode int, body string) *http.Response {
+	mockResponse := &http.Response{
+		StatusCode: statusCode,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+	mockResponse.Header.Set("Content-Type", "application/json")
+
+	return mockResponse
+}
+
 func ReadFile(fileName string) (string, error) {
 	data, err := os.ReadFile("../test/mockResponses/" + fileName)
 	if err != nil {